@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+type backfiller struct {
+	repo        domain.IRefresherRepository
+	fetcher     domain.IRateSourceFetcher
+	checkpoint  *checkpoint
+	pairs       [][2]string
+	concurrency int
+	rateLimit   time.Duration
+	dryRun      bool
+	logger      *slog.Logger
+}
+
+type backfillStats struct {
+	present int
+	missing int
+	fetched int
+	failed  int
+}
+
+// Run backfills every date, skipping dates completed in the checkpoint and keys already stored.
+func (b *backfiller) Run(ctx context.Context, dates []string) error {
+	var limiter <-chan time.Time
+	if b.rateLimit > 0 {
+		ticker := time.NewTicker(b.rateLimit)
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+
+	total := backfillStats{}
+	for _, date := range dates {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if b.checkpoint.isDone(date) {
+			b.logger.Info("date already completed, skipping", "date", date)
+			continue
+		}
+
+		stats, err := b.backfillDate(ctx, date, limiter)
+		total.present += stats.present
+		total.missing += stats.missing
+		total.fetched += stats.fetched
+		total.failed += stats.failed
+		if err != nil {
+			return fmt.Errorf("backfill of %s failed: %w", date, err)
+		}
+		b.logger.Info("date backfilled", "date", date, "present", stats.present, "missing", stats.missing, "fetched", stats.fetched, "failed", stats.failed)
+
+		// only fully backfilled dates are checkpointed, so failed keys are retried on the next run
+		if !b.dryRun && stats.failed == 0 {
+			if err := b.checkpoint.markDone(date); err != nil {
+				return err
+			}
+		}
+	}
+
+	b.logger.Info("backfill completed", "present", total.present, "missing", total.missing, "fetched", total.fetched, "failed", total.failed)
+	return nil
+}
+
+func (b *backfiller) backfillDate(ctx context.Context, date string, limiter <-chan time.Time) (backfillStats, error) {
+	stats := backfillStats{}
+	req := make([]domain.RateKeyRequest, 0, len(b.pairs))
+	for _, pair := range b.pairs {
+		req = append(req, domain.RateKeyRequest{From: pair[0], To: pair[1], Date: date})
+	}
+
+	existing, err := b.repo.BatchGetFromDB(ctx, req)
+	if err != nil {
+		var partial *domain.BatchPartialFailureError
+		if !errors.As(err, &partial) {
+			return stats, err
+		}
+		// unread keys are treated as missing, rewriting them is idempotent
+		b.logger.WarnContext(ctx, "some keys could not be read", "date", date, "error", err)
+	}
+	present := make(map[domain.RateKeyRequest]struct{}, len(existing))
+	for _, rate := range existing {
+		present[rate.RateKeyRequest] = struct{}{}
+	}
+
+	missing := make([]domain.RateKeyRequest, 0, len(req))
+	for _, k := range req {
+		if _, ok := present[k]; ok {
+			stats.present++
+			continue
+		}
+		missing = append(missing, k)
+	}
+	stats.missing = len(missing)
+	if len(missing) == 0 {
+		return stats, nil
+	}
+	if b.dryRun {
+		for _, k := range missing {
+			fmt.Printf("would fetch %s to %s on %s\n", k.From, k.To, k.Date)
+		}
+		return stats, nil
+	}
+
+	rates := b.fetchAll(ctx, missing, limiter)
+	stats.fetched = len(rates)
+	stats.failed = len(missing) - len(rates)
+	if len(rates) == 0 {
+		return stats, nil
+	}
+	if err := b.repo.BatchUpdateDB(ctx, rates, nil); err != nil {
+		var partial *domain.BatchPartialFailureError
+		if !errors.As(err, &partial) {
+			stats.failed += len(rates)
+			stats.fetched = 0
+			return stats, err
+		}
+		// the date is not checkpointed, so the unpersisted keys are retried on the next run
+		b.logger.WarnContext(ctx, "some rates could not be written", "date", date, "error", err)
+		stats.failed += len(partial.FailedWrites)
+		stats.fetched -= len(partial.FailedWrites)
+	}
+	return stats, nil
+}
+
+// fetchAll fetches the keys with at most b.concurrency calls in flight, each waiting on the rate limiter
+func (b *backfiller) fetchAll(ctx context.Context, keys []domain.RateKeyRequest, limiter <-chan time.Time) []domain.RateKey {
+	jobs := make(chan domain.RateKeyRequest)
+	mu := sync.Mutex{}
+	rates := make([]domain.RateKey, 0, len(keys))
+	wg := sync.WaitGroup{}
+	for i := 0; i < b.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				if limiter != nil {
+					select {
+					case <-limiter:
+					case <-ctx.Done():
+						return
+					}
+				}
+				fetched, err := b.fetcher.FetchRateWithSource(ctx, k.From, k.To, k.Date)
+				if err != nil {
+					b.logger.WarnContext(ctx, "failed to fetch rate", "from", k.From, "to", k.To, "date", k.Date, "error", err)
+					continue
+				}
+				mu.Lock()
+				rates = append(rates, domain.RateKey{
+					RateKeyRequest: k,
+					Rate:           fetched.Rate,
+					Provenance: domain.Provenance{
+						Provider:    fetched.Provider,
+						FetchedAt:   time.Now().UTC(),
+						RefreshMode: domain.RefreshModeBackfill,
+					},
+				})
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, k := range keys {
+		select {
+		case jobs <- k:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return rates
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	repository "github.com/ItsDee25/exchange-rate-service/internal/repository/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+)
+
+var testPairs = [][2]string{{"USD", "INR"}, {"EUR", "GBP"}}
+
+// countingFetcher serves a fixed rate for every key, recording the keys fetched
+type countingFetcher struct {
+	mu      sync.Mutex
+	fetched []domain.RateKeyRequest
+}
+
+func (f *countingFetcher) FetchRate(ctx context.Context, from, to, date string) (float64, error) {
+	fetched, err := f.FetchRateWithSource(ctx, from, to, date)
+	return fetched.Rate, err
+}
+
+func (f *countingFetcher) FetchRateWithSource(ctx context.Context, from, to, date string) (domain.FetchedRate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetched = append(f.fetched, domain.RateKeyRequest{From: from, To: to, Date: date})
+	return domain.FetchedRate{Rate: 1.5, Provider: "stub"}, nil
+}
+
+func (f *countingFetcher) Budget() time.Duration {
+	return time.Second
+}
+
+func (f *countingFetcher) fetches() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.fetched)
+}
+
+// testDates returns n consecutive dates ending yesterday, within the retention window
+func testDates(n int) []string {
+	dates := make([]string, n)
+	for i := range dates {
+		dates[i] = time.Now().AddDate(0, 0, i-n).Format(constants.DateLayout)
+	}
+	return dates
+}
+
+func newTestBackfiller(t *testing.T, fake *mocks.DynamoFake, fetcher *countingFetcher, cp *checkpoint) *backfiller {
+	t.Helper()
+	return &backfiller{
+		repo:        repository.NewDynamoRepository(fake, fetcher, repository.NewRateCache()),
+		fetcher:     fetcher,
+		checkpoint:  cp,
+		pairs:       testPairs,
+		concurrency: 2,
+		logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func mustLoadCheckpoint(t *testing.T, path string, pairs [][2]string) *checkpoint {
+	t.Helper()
+	cp, err := loadCheckpoint(path, pairs)
+	if err != nil {
+		t.Fatalf("loadCheckpoint: %v", err)
+	}
+	return cp
+}
+
+func storedKeys(t *testing.T, b *backfiller, dates []string) map[domain.RateKeyRequest]domain.RateKey {
+	t.Helper()
+	req := make([]domain.RateKeyRequest, 0, len(dates)*len(b.pairs))
+	for _, date := range dates {
+		for _, pair := range b.pairs {
+			req = append(req, domain.RateKeyRequest{From: pair[0], To: pair[1], Date: date})
+		}
+	}
+	rates, err := b.repo.BatchGetFromDB(context.Background(), req)
+	if err != nil {
+		t.Fatalf("BatchGetFromDB: %v", err)
+	}
+	stored := make(map[domain.RateKeyRequest]domain.RateKey, len(rates))
+	for _, rate := range rates {
+		stored[rate.RateKeyRequest] = rate
+	}
+	return stored
+}
+
+func TestBackfillerFetchesOnlyMissingKeys(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	fetcher := &countingFetcher{}
+	b := newTestBackfiller(t, fake, fetcher, mustLoadCheckpoint(t, "", testPairs))
+	dates := testDates(3)
+	existing := domain.RateKey{
+		RateKeyRequest: domain.RateKeyRequest{From: "USD", To: "INR", Date: dates[1]},
+		Rate:           83.12,
+		Provenance:     domain.Provenance{Provider: "mock", RefreshMode: domain.RefreshModeScheduled},
+	}
+	if err := b.repo.BatchUpdateDB(context.Background(), []domain.RateKey{existing}, nil); err != nil {
+		t.Fatalf("BatchUpdateDB: %v", err)
+	}
+
+	if err := b.Run(context.Background(), dates); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if got, want := fetcher.fetches(), len(dates)*len(testPairs)-1; got != want {
+		t.Errorf("fetches = %d, want %d, the stored key must not be fetched", got, want)
+	}
+	stored := storedKeys(t, b, dates)
+	if len(stored) != len(dates)*len(testPairs) {
+		t.Errorf("%d keys stored, want %d", len(stored), len(dates)*len(testPairs))
+	}
+	if kept := stored[existing.RateKeyRequest]; kept.Rate != 83.12 || kept.RefreshMode != domain.RefreshModeScheduled {
+		t.Errorf("stored key = %+v, want it left untouched", kept)
+	}
+	for key, rate := range stored {
+		if key != existing.RateKeyRequest && (rate.Rate != 1.5 || rate.RefreshMode != domain.RefreshModeBackfill) {
+			t.Errorf("%+v = %+v, want 1.5 marked backfill", key, rate)
+		}
+	}
+	for _, date := range dates {
+		if !b.checkpoint.isDone(date) {
+			t.Errorf("%s not checkpointed", date)
+		}
+	}
+}
+
+func TestBackfillerDryRunWritesNothing(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	fetcher := &countingFetcher{}
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	b := newTestBackfiller(t, fake, fetcher, mustLoadCheckpoint(t, path, testPairs))
+	b.dryRun = true
+
+	if err := b.Run(context.Background(), testDates(2)); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if fetcher.fetches() != 0 {
+		t.Errorf("fetches = %d, a dry run must not call the provider", fetcher.fetches())
+	}
+	if len(fake.Items()) != 0 || fake.Calls("BatchWriteItem") != 0 {
+		t.Errorf("%d items written by a dry run", len(fake.Items()))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("checkpoint written by a dry run: %v", err)
+	}
+}
+
+func TestBackfillerResumesFromCheckpoint(t *testing.T) {
+	dates := testDates(3)
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	first := mustLoadCheckpoint(t, path, testPairs)
+	if err := first.markDone(dates[0]); err != nil {
+		t.Fatalf("markDone: %v", err)
+	}
+
+	fetcher := &countingFetcher{}
+	b := newTestBackfiller(t, mocks.NewDynamoFake(), fetcher, mustLoadCheckpoint(t, path, testPairs))
+	if err := b.Run(context.Background(), dates); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for _, key := range fetcher.fetched {
+		if key.Date == dates[0] {
+			t.Errorf("%+v fetched, its date was completed by the previous run", key)
+		}
+	}
+	if got, want := fetcher.fetches(), 2*len(testPairs); got != want {
+		t.Errorf("fetches = %d, want %d", got, want)
+	}
+	resumed := mustLoadCheckpoint(t, path, testPairs)
+	for _, date := range dates {
+		if !resumed.isDone(date) {
+			t.Errorf("%s missing from the checkpoint file", date)
+		}
+	}
+}
+
+func TestLoadCheckpointResetsForOtherPairs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	cp := mustLoadCheckpoint(t, path, testPairs)
+	if err := cp.markDone("2024-06-01"); err != nil {
+		t.Fatalf("markDone: %v", err)
+	}
+
+	reordered := mustLoadCheckpoint(t, path, [][2]string{testPairs[1], testPairs[0]})
+	if reordered.reset || !reordered.isDone("2024-06-01") {
+		t.Error("checkpoint of the same pairs in another order discarded")
+	}
+
+	more := mustLoadCheckpoint(t, path, append([][2]string{{"USD", "JPY"}}, testPairs...))
+	if !more.reset || more.isDone("2024-06-01") {
+		t.Error("date completed for other pairs still marked done")
+	}
+}
+
+func TestLoadCheckpointResetsWithoutPairsHash(t *testing.T) {
+	// a checkpoint written before it recorded the pairs
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	data, _ := json.Marshal(map[string]any{"completed": map[string]bool{"2024-06-01": true}})
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cp := mustLoadCheckpoint(t, path, testPairs)
+	if !cp.reset || cp.isDone("2024-06-01") {
+		t.Error("checkpoint without a pairs hash trusted")
+	}
+}
+
+func TestBackfillerRateLimit(t *testing.T) {
+	fetcher := &countingFetcher{}
+	b := newTestBackfiller(t, mocks.NewDynamoFake(), fetcher, mustLoadCheckpoint(t, "", testPairs))
+	b.concurrency = 4
+	b.rateLimit = 20 * time.Millisecond
+	dates := testDates(2)
+
+	start := time.Now()
+	if err := b.Run(context.Background(), dates); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	calls := len(dates) * len(testPairs)
+	if fetcher.fetches() != calls {
+		t.Fatalf("fetches = %d, want %d", fetcher.fetches(), calls)
+	}
+	// every call waits for its own tick, whatever the concurrency
+	if elapsed, want := time.Since(start), time.Duration(calls)*b.rateLimit; elapsed < want {
+		t.Errorf("%d calls took %v, want at least %v", calls, elapsed, want)
+	}
+}
+
+func TestRateLimitInterval(t *testing.T) {
+	tests := []struct {
+		rps     float64
+		want    time.Duration
+		wantErr bool
+	}{
+		{rps: 0, want: 0},
+		{rps: 5, want: 200 * time.Millisecond},
+		{rps: 0.5, want: 2 * time.Second},
+		{rps: 1e9, want: time.Nanosecond},
+		{rps: 2e9, wantErr: true},
+		{rps: math.Inf(1), wantErr: true},
+		{rps: 1e-12, wantErr: true},
+		{rps: -1, wantErr: true},
+		{rps: math.NaN(), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := rateLimitInterval(tt.rps)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("rateLimitInterval(%v) = %v, want an error", tt.rps, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("rateLimitInterval(%v) = %v, %v, want %v", tt.rps, got, err, tt.want)
+		}
+	}
+}
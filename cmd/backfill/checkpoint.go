@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// checkpoint records the dates that were fully backfilled so an interrupted run can resume. A date is
+// only complete for the pairs it was backfilled with, so the checkpoint is bound to a hash of the pairs.
+type checkpoint struct {
+	path      string
+	PairsHash string          `json:"pairs_hash"`
+	Completed map[string]bool `json:"completed"`
+	// reset is set when a checkpoint recorded for other pairs was discarded
+	reset bool
+}
+
+// loadCheckpoint reads the checkpoint of a backfill of pairs, starting over when the file was recorded
+// for other pairs
+func loadCheckpoint(path string, pairs [][2]string) (*checkpoint, error) {
+	cp := &checkpoint{path: path, PairsHash: pairsHash(pairs), Completed: map[string]bool{}}
+	if path == "" {
+		return cp, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", path, err)
+	}
+	stored := checkpoint{}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if stored.PairsHash != cp.PairsHash {
+		cp.reset = true
+		return cp, nil
+	}
+	for date, done := range stored.Completed {
+		cp.Completed[date] = done
+	}
+	return cp, nil
+}
+
+// pairsHash identifies a set of pairs regardless of their order
+func pairsHash(pairs [][2]string) string {
+	keys := make([]string, len(pairs))
+	for i, pair := range pairs {
+		keys[i] = pair[0] + "-" + pair[1]
+	}
+	sort.Strings(keys)
+	sum := sha256.Sum256([]byte(strings.Join(keys, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *checkpoint) isDone(date string) bool {
+	return c.Completed[date]
+}
+
+// markDone records the date and rewrites the checkpoint file atomically
+func (c *checkpoint) markDone(date string) error {
+	c.Completed[date] = true
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
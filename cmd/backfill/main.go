@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	infra "github.com/ItsDee25/exchange-rate-service/infra/ratefetcher"
+	"github.com/ItsDee25/exchange-rate-service/internal/config"
+	repository "github.com/ItsDee25/exchange-rate-service/internal/repository/currency"
+	usecase "github.com/ItsDee25/exchange-rate-service/internal/usecase/currency"
+	pkg "github.com/ItsDee25/exchange-rate-service/pkg/awsclient"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+	"github.com/ItsDee25/exchange-rate-service/pkg/logger"
+	"github.com/ItsDee25/exchange-rate-service/pkg/timeutil"
+)
+
+// backfill fills the historical rates of the configured pairs, e.g.
+//
+//	go run ./cmd/backfill -start 2024-03-01 -end 2024-05-30 -concurrency 4 -rps 5
+func main() {
+	today := time.Now().Format(constants.DateLayout)
+	start := flag.String("start", "", "first date to backfill (YYYY-MM-DD); defaults to the oldest date within the retention window")
+	end := flag.String("end", today, "last date to backfill (YYYY-MM-DD)")
+	pairsFlag := flag.String("pairs", "", "comma separated pairs such as USD-INR,EUR-GBP; defaults to every pair of enabled currencies")
+	concurrency := flag.Int("concurrency", 4, "maximum concurrent provider calls")
+	rps := flag.Float64("rps", 5, "maximum provider calls per second, 0 disables rate limiting")
+	checkpointPath := flag.String("checkpoint", "backfill.checkpoint.json", "file recording completed dates, empty disables resuming")
+	dryRun := flag.Bool("dry-run", false, "print the missing keys without fetching or writing them")
+	flag.Parse()
+
+	if err := run(*start, *end, *pairsFlag, *concurrency, *rps, *checkpointPath, *dryRun); err != nil {
+		slog.Error("backfill failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(start, end, pairsFlag string, concurrency int, rps float64, checkpointPath string, dryRun bool) error {
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	appLogger, err := logger.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	slog.SetDefault(appLogger)
+	log := appLogger.With("component", "Backfill")
+	if start == "" {
+		start = time.Now().AddDate(0, 0, 1-cfg.Currency.RetentionDays).Format(constants.DateLayout)
+	}
+	dates, err := timeutil.DatesBetween(start, end)
+	if err != nil {
+		return err
+	}
+	var pairs [][2]string
+	if pairsFlag != "" {
+		if pairs, err = config.ParsePairs(strings.Split(pairsFlag, ",")); err != nil {
+			return err
+		}
+	}
+	if concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", concurrency)
+	}
+	rateLimit, err := rateLimitInterval(rps)
+	if err != nil {
+		return err
+	}
+
+	// stop on SIGINT/SIGTERM, completed dates stay in the checkpoint
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dynamoClient, err := pkg.NewDynamoClient(ctx, cfg.Dynamo.Region, cfg.Dynamo.Endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to initialize DynamoDB client: %w", err)
+	}
+	if pairs == nil {
+		// default to the pairs kept fresh by the refresher, seeding the registry like the server does
+		registry := repository.NewCurrencyRegistry(dynamoClient).WithTableName(cfg.Dynamo.Table).WithLogger(appLogger)
+		if err := usecase.NewCurrencyRegistryUsecase(registry).SeedCurrencies(ctx, cfg.Currency.Supported); err != nil {
+			return fmt.Errorf("failed to load currency registry: %w", err)
+		}
+		pairs = registry.EnabledPairs(ctx)
+	}
+	cp, err := loadCheckpoint(checkpointPath, pairs)
+	if err != nil {
+		return err
+	}
+	if cp.reset {
+		log.Warn("checkpoint was recorded for other pairs, starting over", "checkpoint", checkpointPath)
+	}
+	fetcher, err := infra.NewFromConfig(cfg.RateProvider, &http.Client{Timeout: cfg.Server.HTTPClientTimeout.Duration()})
+	if err != nil {
+		return fmt.Errorf("failed to initialize rate provider: %w", err)
+	}
+	fetcher.WithLogger(appLogger)
+
+	b := &backfiller{
+		repo: repository.NewDynamoRepository(dynamoClient, fetcher, repository.NewRateCache()).
+			WithTableName(cfg.Dynamo.Table).
+			WithRetention(cfg.Currency.Retention()).
+			WithLogger(appLogger),
+		fetcher:     fetcher,
+		checkpoint:  cp,
+		pairs:       pairs,
+		concurrency: concurrency,
+		rateLimit:   rateLimit,
+		dryRun:      dryRun,
+		logger:      log,
+	}
+	log.Info("backfilling rates", "pairs", len(pairs), "start", start, "end", end, "dry_run", dryRun)
+	return b.Run(ctx, dates)
+}
+
+// rateLimitInterval returns the interval between provider calls for at most rps calls per second, zero
+// when rps is 0 and rate limiting is disabled
+func rateLimitInterval(rps float64) (time.Duration, error) {
+	if rps == 0 {
+		return 0, nil
+	}
+	if math.IsNaN(rps) || rps < 0 {
+		return 0, fmt.Errorf("rps must not be negative, got %v", rps)
+	}
+	interval := float64(time.Second) / rps
+	if interval < 1 || interval > math.MaxInt64 {
+		return 0, fmt.Errorf("rps must be between %v and %v, got %v", float64(time.Second)/math.MaxInt64, float64(time.Second), rps)
+	}
+	return time.Duration(interval), nil
+}
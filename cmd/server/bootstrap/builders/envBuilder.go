@@ -1,21 +1,32 @@
 package builders
 
 import (
+	"log/slog"
 	"net/http"
 
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/ItsDee25/exchange-rate-service/internal/config"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	pkg "github.com/ItsDee25/exchange-rate-service/pkg/awsclient"
 )
 
 type env struct {
+	Config       config.Config
 	HttpClient   *http.Client
-	DynamoClient *dynamodb.Client
+	DynamoClient pkg.DynamoAPI
+	RateFetcher  domain.IRateSourceFetcher
+	Logger       *slog.Logger
 }
 
 func NewEnv() *env {
 	return &env{}
 }
 
-func (e *env) WithDynamoClient(c *dynamodb.Client) *env {
+func (e *env) WithConfig(cfg config.Config) *env {
+	e.Config = cfg
+	return e
+}
+
+func (e *env) WithDynamoClient(c pkg.DynamoAPI) *env {
 	e.DynamoClient = c
 	return e
 }
@@ -24,3 +35,13 @@ func (e *env) WithHTTPClient(c *http.Client) *env {
 	e.HttpClient = c
 	return e
 }
+
+func (e *env) WithRateFetcher(f domain.IRateSourceFetcher) *env {
+	e.RateFetcher = f
+	return e
+}
+
+func (e *env) WithLogger(l *slog.Logger) *env {
+	e.Logger = l
+	return e
+}
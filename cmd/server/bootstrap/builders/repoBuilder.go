@@ -2,13 +2,16 @@ package builders
 
 import (
 	infra "github.com/ItsDee25/exchange-rate-service/infra/ratefetcher"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
 	repository "github.com/ItsDee25/exchange-rate-service/internal/repository/currency"
 )
 
 type repositories struct {
 	CurrencyDynamoRepository *repository.CurrencyDynamoRepository
-	CurrecyCache *repository.RateCache
+	CurrecyCache domain.IRateCache
 	DynamoLocker *infra.DynamoLocker
+	WriteBehindQueue *repository.WriteBehindQueue
+	CurrencyRegistry *repository.CurrencyRegistry
 }
 
 func NewRepositories() *repositories {
@@ -20,7 +23,7 @@ func (r *repositories) WithCurrencyRepository(repo *repository.CurrencyDynamoRep
 	return r
 }
 
-func (r *repositories) WithCurrencyCache(c *repository.RateCache) *repositories {
+func (r *repositories) WithCurrencyCache(c domain.IRateCache) *repositories {
 	r.CurrecyCache = c
 	return r
 }
@@ -28,4 +31,13 @@ func (r *repositories) WithCurrencyCache(c *repository.RateCache) *repositories
 func (r *repositories) WithDynamoLocker(l *infra.DynamoLocker) *repositories {
 	r.DynamoLocker = l
 	return r
-}
\ No newline at end of file
+}
+func (r *repositories) WithWriteBehindQueue(q *repository.WriteBehindQueue) *repositories {
+	r.WriteBehindQueue = q
+	return r
+}
+
+func (r *repositories) WithCurrencyRegistry(registry *repository.CurrencyRegistry) *repositories {
+	r.CurrencyRegistry = registry
+	return r
+}
@@ -3,7 +3,8 @@ package builders
 import usecase "github.com/ItsDee25/exchange-rate-service/internal/usecase/currency"
 
 type Usecases struct {
-	CurrencyUsecase *usecase.CurrencyUsecase
+	CurrencyUsecase         *usecase.CurrencyUsecase
+	CurrencyRegistryUsecase *usecase.CurrencyRegistryUsecase
 }
 
 func NewUsecases() *Usecases {
@@ -14,3 +15,8 @@ func (u *Usecases) WithCurrencyUsecase(c *usecase.CurrencyUsecase) *Usecases {
 	u.CurrencyUsecase = c
 	return u
 }
+
+func (u *Usecases) WithCurrencyRegistryUsecase(c *usecase.CurrencyRegistryUsecase) *Usecases {
+	u.CurrencyRegistryUsecase = c
+	return u
+}
@@ -0,0 +1,16 @@
+package bootstrap
+
+import (
+	"github.com/ItsDee25/exchange-rate-service/internal/config"
+	repository "github.com/ItsDee25/exchange-rate-service/internal/repository/currency"
+)
+
+// newRateCache builds the bounded rate cache, never caching rates older than the retention window
+func newRateCache(cfg config.Config) *repository.LRURateCache {
+	return repository.NewLRURateCache().
+		WithMaxEntries(cfg.Cache.MaxEntries).
+		WithTTL(cfg.Cache.CurrentTTL.Duration(), cfg.Cache.HistoricalTTL.Duration()).
+		WithNegativeTTL(cfg.Cache.NegativeTTL.Duration()).
+		WithMaxNegativeEntries(cfg.Cache.NegativeMaxEntries).
+		WithRetention(cfg.Currency.Retention())
+}
@@ -0,0 +1,31 @@
+package bootstrap
+
+import (
+	"github.com/ItsDee25/exchange-rate-service/internal/config"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/health"
+	repository "github.com/ItsDee25/exchange-rate-service/internal/repository/currency"
+	jobs "github.com/ItsDee25/exchange-rate-service/jobs/currency"
+)
+
+// newReadiness builds the checks of /readyz. Dynamo is critical: without it rates are not served. The
+// warm-up is critical so traffic only arrives once the cache is loaded. An empty cache, a provider outage
+// or a stale refresher only degrade the instance, which still serves the rates stored in dynamo; a stale
+// refresher usually follows a provider outage and failing every instance would turn it into a full outage.
+func newReadiness(
+	cfg config.HealthConfig,
+	repo *repository.CurrencyDynamoRepository,
+	cache *repository.LRURateCache,
+	fetcher domain.IRateSourceFetcher,
+	registry domain.ICurrencyRegistry,
+	refresher *jobs.RateRefresher,
+	warmer *jobs.CacheWarmer,
+) *health.Readiness {
+	return health.NewReadiness().
+		WithTimeout(cfg.CheckTimeout.Duration()).
+		Register("dynamo", health.CheckerFunc(repo.Ping)).
+		Register("warmup", health.Completed(warmer.Warmed)).
+		RegisterOptional("refresher", health.Freshness(refresher.LastSuccess, cfg.RefresherMaxAge.Duration())).
+		RegisterOptional("cache", health.NotEmpty(cache.Len)).
+		RegisterOptional("provider", health.Cached(health.Provider(fetcher, registry), cfg.ProviderCheckInterval.Duration()))
+}
@@ -2,66 +2,176 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"net/http"
-	"time"
+	"os"
 
 	"github.com/ItsDee25/exchange-rate-service/cmd/server/bootstrap/builders"
 	infra "github.com/ItsDee25/exchange-rate-service/infra/ratefetcher"
-	constants "github.com/ItsDee25/exchange-rate-service/internal/constants/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/config"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/metrics"
 	repository "github.com/ItsDee25/exchange-rate-service/internal/repository/currency"
 	"github.com/ItsDee25/exchange-rate-service/internal/router"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
 	usecase "github.com/ItsDee25/exchange-rate-service/internal/usecase/currency"
 	jobs "github.com/ItsDee25/exchange-rate-service/jobs/currency"
-	"github.com/ItsDee25/exchange-rate-service/mocks"
 	pkg "github.com/ItsDee25/exchange-rate-service/pkg/awsclient"
+	"github.com/ItsDee25/exchange-rate-service/pkg/lifecycle"
+	"github.com/ItsDee25/exchange-rate-service/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
 
 func InitServer() {
 
-	r := gin.Default()
+	cfg, err := config.Load("")
+	if err != nil {
+		panic("Failed to load config: " + err.Error())
+	}
+
+	appLogger, err := logger.New(os.Stdout, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		panic("Failed to initialize logger: " + err.Error())
+	}
+	// components built without a logger, and third party code, log through the default logger
+	slog.SetDefault(appLogger)
+
+	// request logging and recovery are registered by the router
+	r := gin.New()
 
 	ctx := context.Background()
 
-	dynamoClient, err := pkg.NewDynamoClient(ctx)
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		File:        cfg.Tracing.File,
+		Endpoint:    cfg.Tracing.OTLPEndpoint,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: cfg.Tracing.ServiceName,
+	})
+	if err != nil {
+		panic("Failed to initialize tracing: " + err.Error())
+	}
+
+	dynamoClient, err := pkg.NewDynamoClient(ctx, cfg.Dynamo.Region, cfg.Dynamo.Endpoint)
 	if err != nil {
 		panic("Failed to initialize DynamoDB client: " + err.Error())
 	}
 
 	// build env
 	env := builders.NewEnv().
-		WithDynamoClient(dynamoClient).
-		WithHTTPClient(&http.Client{Timeout: 10 * time.Second})
+		WithConfig(cfg).
+		WithLogger(appLogger).
+		WithDynamoClient(tracing.NewTracedDynamo(metrics.NewInstrumentedDynamo(dynamoClient))).
+		WithHTTPClient(&http.Client{Timeout: cfg.Server.HTTPClientTimeout.Duration()})
+
+	// build rate provider, failing fast if it is misconfigured
+	rateFetcher, err := infra.NewFromConfig(env.Config.RateProvider, env.HttpClient)
+	if err != nil {
+		panic("Failed to initialize rate provider: " + err.Error())
+	}
+	env.WithRateFetcher(rateFetcher.WithLogger(env.Logger))
 
 	// build repositories
-	cache := repository.NewRateCache()
+	cache := newRateCache(env.Config)
+	currencyRepo := repository.NewDynamoRepository(env.DynamoClient, env.RateFetcher, cache).
+		WithTableName(env.Config.Dynamo.Table).
+		WithRetention(env.Config.Currency.Retention()).
+		WithLogger(env.Logger)
+	// the queue retries the failed rates itself, each write is a single attempt
+	writeBehind := repository.NewWriteBehindQueue(currencyRepo.BatchUpdateDBOnce).
+		WithCapacity(env.Config.WriteBehind.Capacity).
+		WithWorkers(env.Config.WriteBehind.Workers).
+		WithBatching(0, env.Config.WriteBehind.FlushInterval.Duration()).
+		WithMaxAttempts(env.Config.WriteBehind.MaxAttempts).
+		WithLogger(env.Logger)
 	repositories := builders.NewRepositories().
 		WithCurrencyCache(cache).
-		WithCurrencyRepository(repository.NewDynamoRepository(env.DynamoClient, mocks.NewMockRateFetcher(), cache)).
-		WithDynamoLocker(infra.NewDynamoLocker(env.DynamoClient))
+		WithCurrencyRepository(currencyRepo.WithWriteBehind(writeBehind)).
+		WithWriteBehindQueue(writeBehind).
+		WithDynamoLocker(infra.NewDynamoLocker(env.DynamoClient).WithTableName(env.Config.Dynamo.Table)).
+		WithCurrencyRegistry(repository.NewCurrencyRegistry(env.DynamoClient).
+			WithTableName(env.Config.Dynamo.Table).
+			WithLogger(env.Logger))
+	writeBehind.Start()
+	metrics.RegisterCacheSize(cache.Len)
+	metrics.RegisterCoalescing(currencyRepo.CoalescingStats)
+	metrics.RegisterWriteBehind(writeBehind.Stats)
 
 	// build usecases
 
+	// the rounding mode was validated by config.Load
+	roundingMode, _ := domain.ParseRoundingMode(env.Config.Currency.RoundingMode)
 	usecases := builders.NewUsecases().
-		WithCurrencyUsecase(usecase.NewCurrencyUsecase(repositories.CurrencyDynamoRepository))
+		WithCurrencyUsecase(usecase.NewCurrencyUsecase(repositories.CurrencyDynamoRepository, repositories.CurrencyRegistry).
+			WithTriangulation(env.Config.Currency.TriangulationPivots).
+			WithRoundingMode(roundingMode).
+			WithLogger(env.Logger)).
+		WithCurrencyRegistryUsecase(usecase.NewCurrencyRegistryUsecase(repositories.CurrencyRegistry))
+
+	// register the configured currencies missing from the registry and load it
+	if err := usecases.CurrencyRegistryUsecase.SeedCurrencies(ctx, env.Config.Currency.Supported); err != nil {
+		panic("Failed to initialize currency registry: " + err.Error())
+	}
 
-	router.RegisterRoutes(r, usecases)
+	// start cron jobs, they stop when jobsCtx is cancelled on shutdown
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
 
-	// start cron jobs
+	// load recent rates into the cache in the background, the instance is not ready until it is done
+	warmer := jobs.NewCacheWarmer(
+		repositories.CurrencyDynamoRepository,
+		repositories.CurrencyRegistry,
+		env.Config.Cache.WarmupDays,
+		env.Config.Cache.WarmupTimeout.Duration(),
+	).WithLogger(env.Logger)
+	warmer.Start(jobsCtx)
 
 	refresher := jobs.NewRateRefresher(
 		repositories.CurrencyDynamoRepository,
-		mocks.NewMockRateFetcher(),
+		env.RateFetcher,
 		repositories.DynamoLocker,
-		constants.SupportedCurrencyPairs,
-	)
-	refresher.Start()
-
-	cacheCleaner := jobs.NewCacheCleaner(repositories.CurrecyCache)
-
-	cacheCleaner.Start()
-
-	if err := r.Run(":8080"); err != nil {
-		panic("Failed to start server: " + err.Error())
+		repositories.CurrencyRegistry,
+		env.Config.Jobs.RefreshInterval.Duration(),
+		env.Config.Jobs.RefreshLockTTL.Duration(),
+		env.Config.Jobs.RefreshConcurrency,
+	).WithLogger(env.Logger)
+	refresher.Start(jobsCtx)
+
+	cacheCleaner := jobs.NewCacheCleaner(repositories.CurrecyCache, env.Config.Jobs.CacheCleanInterval.Duration()).
+		WithLogger(env.Logger)
+
+	cacheCleaner.Start(jobsCtx)
+
+	registryReloader := jobs.NewRegistryReloader(repositories.CurrencyRegistry, env.Config.Jobs.RegistryReloadInterval.Duration()).
+		WithLogger(env.Logger)
+	registryReloader.Start(jobsCtx)
+
+	readiness := newReadiness(env.Config.Health, repositories.CurrencyDynamoRepository, cache, env.RateFetcher, repositories.CurrencyRegistry, refresher, warmer)
+	router.RegisterRoutes(r, usecases, readiness, env.Config, env.Logger)
+
+	srv := &http.Server{Addr: env.Config.Server.Addr(), Handler: r}
+
+	// on SIGTERM/SIGINT: stop accepting requests and drain in-flight ones, stop the jobs letting
+	// a running refresh finish, flush the rates queued by cache misses, then export the buffered spans
+	err = lifecycle.NewManager(env.Config.Server.ShutdownTimeout.Duration()).
+		WithLogger(env.Logger).
+		OnShutdown("http server", srv.Shutdown).
+		OnShutdown("jobs", func(ctx context.Context) error {
+			stopJobs()
+			return errors.Join(warmer.Wait(ctx), refresher.Wait(ctx), cacheCleaner.Wait(ctx), registryReloader.Wait(ctx))
+		}).
+		OnShutdown("write-behind queue", repositories.WriteBehindQueue.Drain).
+		OnShutdown("tracing", shutdownTracing).
+		Run(func() error {
+			env.Logger.Info("server listening", "addr", srv.Addr)
+			if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
+	if err != nil {
+		panic("Server stopped with error: " + err.Error())
 	}
+	env.Logger.Info("server shut down gracefully")
 }
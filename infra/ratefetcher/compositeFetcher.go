@@ -0,0 +1,330 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/metrics"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultProviderTimeout = 3 * time.Second
+
+// minQuorum is the number of providers that must answer and agree for a quorum rate
+const minQuorum = 2
+
+type NamedFetcher struct {
+	Name    string
+	Fetcher domain.IRateFetcher
+	Timeout time.Duration
+}
+
+// CompositeFetcher queries an ordered list of providers. By default it falls back to the next
+// provider on error; in quorum mode it queries all of them and takes the median on disagreement,
+// failing unless at least minQuorum of them answered and agree with it.
+type CompositeFetcher struct {
+	providers []NamedFetcher
+	quorum    bool
+	tolerance float64
+	logger    *slog.Logger
+}
+
+func NewCompositeFetcher(providers []NamedFetcher) *CompositeFetcher {
+	for i := range providers {
+		if providers[i].Timeout <= 0 {
+			providers[i].Timeout = defaultProviderTimeout
+		}
+	}
+	return &CompositeFetcher{
+		providers: providers,
+		logger:    slog.Default().With("component", "CompositeFetcher"),
+	}
+}
+
+func (c *CompositeFetcher) WithLogger(logger *slog.Logger) *CompositeFetcher {
+	c.logger = logger.With("component", "CompositeFetcher")
+	return c
+}
+
+// WithQuorum enables quorum mode; tolerance is the allowed relative deviation from the median.
+func (c *CompositeFetcher) WithQuorum(tolerance float64) *CompositeFetcher {
+	c.quorum = true
+	c.tolerance = tolerance
+	return c
+}
+
+// Budget implements IRateSourceFetcher: providers are tried one after the other in failover mode,
+// all at once in quorum mode
+func (c *CompositeFetcher) Budget() time.Duration {
+	var budget time.Duration
+	for _, p := range c.providers {
+		if c.quorum {
+			budget = max(budget, p.Timeout)
+		} else {
+			budget += p.Timeout
+		}
+	}
+	return budget
+}
+
+// FetchRate implements IRateFetcher
+func (c *CompositeFetcher) FetchRate(ctx context.Context, from, to, date string) (float64, error) {
+	fetched, err := c.FetchRateWithSource(ctx, from, to, date)
+	if err != nil {
+		return 0, err
+	}
+	return fetched.Rate, nil
+}
+
+// FetchRateWithSource implements IRateSourceFetcher
+func (c *CompositeFetcher) FetchRateWithSource(ctx context.Context, from, to, date string) (fetched domain.FetchedRate, err error) {
+	ctx, span := tracing.Start(ctx, "CompositeFetcher.FetchRate", append(tracing.RateAttrs(from, to, date), attribute.Bool("quorum", c.quorum))...)
+	defer func() {
+		if fetched.Provider != "" {
+			span.SetAttributes(tracing.AttrProvider.String(fetched.Provider))
+		}
+		tracing.End(span, err)
+	}()
+
+	if len(c.providers) == 0 {
+		return domain.FetchedRate{}, fmt.Errorf("%w: no rate providers configured", domain.ErrProviderFailed)
+	}
+	if c.quorum {
+		return c.fetchQuorum(ctx, from, to, date)
+	}
+	return c.fetchFailover(ctx, from, to, date)
+}
+
+func (c *CompositeFetcher) fetchFailover(ctx context.Context, from, to, date string) (domain.FetchedRate, error) {
+	errs := make([]error, 0, len(c.providers))
+	for _, p := range c.providers {
+		rate, err := fetchWithTimeout(ctx, p, from, to, date)
+		if err != nil {
+			c.logger.WarnContext(ctx, "rate provider failed", "provider", p.Name, "from", from, "to", to, "date", date, "error", err)
+			trace.SpanFromContext(ctx).AddEvent("provider failed", trace.WithAttributes(tracing.AttrProvider.String(p.Name), attribute.String("error", err.Error())))
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		return domain.FetchedRate{Rate: rate, Provider: p.Name}, nil
+	}
+	return domain.FetchedRate{}, allProvidersFailed(errs)
+}
+
+// allProvidersFailed joins the provider errors. The result only matches ErrRateUnavailable when every provider
+// reported the rate unavailable, a timeout or outage of any of them means the rate may still exist. It then
+// matches ErrProviderUnavailable when the providers that did not report the rate unavailable all timed out,
+// ErrProviderFailed otherwise.
+func allProvidersFailed(errs []error) error {
+	joined := errors.Join(errs...)
+	var cause error
+	for _, err := range errs {
+		switch {
+		case errors.Is(err, domain.ErrRateUnavailable):
+		case errors.Is(err, context.DeadlineExceeded):
+			if cause == nil {
+				cause = domain.ErrProviderUnavailable
+			}
+		default:
+			cause = domain.ErrProviderFailed
+		}
+	}
+	if cause == nil {
+		return fmt.Errorf("all rate providers failed: %w", joined)
+	}
+	return fmt.Errorf("all rate providers failed: %w: %w", cause, transientError{joined})
+}
+
+// transientError hides ErrRateUnavailable from errors.Is while keeping the other wrapped errors matchable
+type transientError struct {
+	err error
+}
+
+func (e transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e transientError) Is(target error) bool {
+	return target != domain.ErrRateUnavailable && errors.Is(e.err, target)
+}
+
+type providerResult struct {
+	name string
+	rate float64
+	err  error
+}
+
+func (c *CompositeFetcher) fetchQuorum(ctx context.Context, from, to, date string) (domain.FetchedRate, error) {
+	results := make([]providerResult, len(c.providers))
+	wg := sync.WaitGroup{}
+	for i, p := range c.providers {
+		wg.Add(1)
+		go func(i int, p NamedFetcher) {
+			defer wg.Done()
+			rate, err := fetchWithTimeout(ctx, p, from, to, date)
+			results[i] = providerResult{name: p.Name, rate: rate, err: err}
+		}(i, p)
+	}
+	wg.Wait()
+
+	succeeded := make([]providerResult, 0, len(results))
+	errs := make([]error, 0, len(results))
+	for _, res := range results {
+		if res.err != nil {
+			c.logger.WarnContext(ctx, "rate provider failed", "provider", res.name, "from", from, "to", to, "date", date, "error", res.err)
+			trace.SpanFromContext(ctx).AddEvent("provider failed", trace.WithAttributes(tracing.AttrProvider.String(res.name), attribute.String("error", res.err.Error())))
+			errs = append(errs, fmt.Errorf("%s: %w", res.name, res.err))
+			continue
+		}
+		succeeded = append(succeeded, res)
+	}
+	if len(succeeded) == 0 {
+		return domain.FetchedRate{}, allProvidersFailed(errs)
+	}
+	if len(succeeded) < minQuorum {
+		// a single answer cannot be checked against another provider
+		return domain.FetchedRate{}, noQuorum(fmt.Sprintf("only %s answered", succeeded[0].name), errs)
+	}
+
+	median := medianRate(succeeded)
+	if withinTolerance(succeeded, median, c.tolerance) {
+		// providers agree, prefer the highest priority one
+		return domain.FetchedRate{Rate: succeeded[0].rate, Provider: succeeded[0].name}, nil
+	}
+
+	names := make([]string, 0, len(succeeded))
+	for _, res := range succeeded {
+		names = append(names, fmt.Sprintf("%s=%v", res.name, res.rate))
+	}
+	rates := strings.Join(names, ", ")
+	if agreeing(succeeded, median, c.tolerance) < minQuorum {
+		c.logger.WarnContext(ctx, "rate providers disagree beyond tolerance, no quorum",
+			"tolerance", c.tolerance, "from", from, "to", to, "date", date, "median", median, "rates", rates)
+		return domain.FetchedRate{}, noQuorum("providers disagree: "+rates, errs)
+	}
+	c.logger.WarnContext(ctx, "rate providers disagree beyond tolerance, using median",
+		"tolerance", c.tolerance, "from", from, "to", to, "date", date, "median", median, "rates", rates)
+
+	return domain.FetchedRate{Rate: median, Provider: medianProviderName(succeeded)}, nil
+}
+
+// noQuorum reports a quorum that was not reached. Some provider served the rate, so the result never
+// matches ErrRateUnavailable. It matches ErrProviderUnavailable when the missing providers all timed out,
+// ErrProviderFailed otherwise.
+func noQuorum(reason string, errs []error) error {
+	cause := domain.ErrProviderFailed
+	if len(errs) > 0 {
+		cause = domain.ErrProviderUnavailable
+		for _, err := range errs {
+			if !errors.Is(err, context.DeadlineExceeded) {
+				cause = domain.ErrProviderFailed
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return fmt.Errorf("rate provider quorum not reached, %s: %w", reason, cause)
+	}
+	return fmt.Errorf("rate provider quorum not reached, %s: %w: %w", reason, cause, transientError{errors.Join(errs...)})
+}
+
+// fetchWithTimeout enforces the provider timeout even if the provider ignores its context,
+// recording the latency and outcome of the call.
+func fetchWithTimeout(ctx context.Context, p NamedFetcher, from, to, date string) (float64, error) {
+	start := time.Now()
+	rate, err := fetchWithDeadline(ctx, p, from, to, date)
+
+	outcome := metrics.OutcomeSuccess
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		outcome = metrics.OutcomeTimeout
+	case errors.Is(err, domain.ErrRateUnavailable):
+		outcome = metrics.OutcomeUnavailable
+	case err != nil:
+		outcome = metrics.OutcomeError
+	}
+	metrics.ProviderDuration.WithLabelValues(p.Name, outcome).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.ProviderErrors.WithLabelValues(p.Name, outcome).Inc()
+	}
+	return rate, err
+}
+
+func fetchWithDeadline(ctx context.Context, p NamedFetcher, from, to, date string) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	type fetchResult struct {
+		rate float64
+		err  error
+	}
+	done := make(chan fetchResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fetchResult{err: fmt.Errorf("provider panicked: %v", r)}
+			}
+		}()
+		rate, err := p.Fetcher.FetchRate(ctx, from, to, date)
+		done <- fetchResult{rate: rate, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.rate, res.err
+	case <-ctx.Done():
+		return 0, fmt.Errorf("provider timed out after %v: %w", p.Timeout, ctx.Err())
+	}
+}
+
+func medianRate(results []providerResult) float64 {
+	rates := make([]float64, len(results))
+	for i, res := range results {
+		rates[i] = res.rate
+	}
+	sort.Float64s(rates)
+	mid := len(rates) / 2
+	if len(rates)%2 == 0 {
+		return (rates[mid-1] + rates[mid]) / 2
+	}
+	return rates[mid]
+}
+
+func withinTolerance(results []providerResult, median, tolerance float64) bool {
+	for _, res := range results {
+		if math.Abs(res.rate-median) > tolerance*median {
+			return false
+		}
+	}
+	return true
+}
+
+// agreeing counts the results within tolerance of the median
+func agreeing(results []providerResult, median, tolerance float64) int {
+	n := 0
+	for _, res := range results {
+		if math.Abs(res.rate-median) <= tolerance*median {
+			n++
+		}
+	}
+	return n
+}
+
+func medianProviderName(results []providerResult) string {
+	names := make([]string, len(results))
+	for i, res := range results {
+		names[i] = res.name
+	}
+	return fmt.Sprintf("median(%s)", strings.Join(names, ","))
+}
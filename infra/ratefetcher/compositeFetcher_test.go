@@ -0,0 +1,296 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+const testProviderTimeout = 20 * time.Millisecond
+
+// stubProvider answers rate or err, after delay unless its context is done first
+type stubProvider struct {
+	rate  float64
+	err   error
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (p *stubProvider) FetchRate(ctx context.Context, from, to, date string) (float64, error) {
+	p.calls.Add(1)
+	if p.delay > 0 {
+		select {
+		case <-time.After(p.delay):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	return p.rate, p.err
+}
+
+func answers(rate float64) *stubProvider {
+	return &stubProvider{rate: rate}
+}
+
+func fails(err error) *stubProvider {
+	return &stubProvider{err: err}
+}
+
+func hangs() *stubProvider {
+	return &stubProvider{rate: 1, delay: time.Second}
+}
+
+func newTestComposite(stubs ...*stubProvider) *CompositeFetcher {
+	names := []string{"a", "b", "c"}
+	providers := make([]NamedFetcher, len(stubs))
+	for i, stub := range stubs {
+		providers[i] = NamedFetcher{Name: names[i], Fetcher: stub, Timeout: testProviderTimeout}
+	}
+	return NewCompositeFetcher(providers)
+}
+
+// wantErrs lists the sentinel errors a failed fetch must match, and those it must not
+type wantErrs struct {
+	is    []error
+	isNot []error
+}
+
+func (w wantErrs) check(t *testing.T, err error) {
+	t.Helper()
+	for _, target := range w.is {
+		if !errors.Is(err, target) {
+			t.Errorf("err = %v, want it to match %v", err, target)
+		}
+	}
+	for _, target := range w.isNot {
+		if errors.Is(err, target) {
+			t.Errorf("err = %v, want it not to match %v", err, target)
+		}
+	}
+}
+
+var (
+	errBadResponse     = errors.New("bad response")
+	wantRateMissing    = wantErrs{is: []error{domain.ErrRateUnavailable}, isNot: []error{domain.ErrProviderFailed, domain.ErrProviderUnavailable}}
+	wantProviderDown   = wantErrs{is: []error{domain.ErrProviderUnavailable, context.DeadlineExceeded}, isNot: []error{domain.ErrRateUnavailable, domain.ErrProviderFailed}}
+	wantProviderFailed = wantErrs{is: []error{domain.ErrProviderFailed}, isNot: []error{domain.ErrRateUnavailable, domain.ErrProviderUnavailable}}
+)
+
+func TestCompositeFetcherFailover(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers []*stubProvider
+		want      domain.FetchedRate
+		wantErr   *wantErrs
+	}{
+		{
+			name:      "first provider answers",
+			providers: []*stubProvider{answers(1.1), answers(1.2)},
+			want:      domain.FetchedRate{Rate: 1.1, Provider: "a"},
+		},
+		{
+			name:      "fails over after a timeout",
+			providers: []*stubProvider{hangs(), answers(1.2)},
+			want:      domain.FetchedRate{Rate: 1.2, Provider: "b"},
+		},
+		{
+			name:      "fails over after an error",
+			providers: []*stubProvider{fails(errBadResponse), fails(domain.ErrRateUnavailable), answers(1.3)},
+			want:      domain.FetchedRate{Rate: 1.3, Provider: "c"},
+		},
+		{
+			name:      "every provider reports the rate unavailable",
+			providers: []*stubProvider{fails(domain.ErrRateUnavailable), fails(domain.ErrRateUnavailable)},
+			wantErr:   &wantRateMissing,
+		},
+		{
+			name:      "every provider times out",
+			providers: []*stubProvider{hangs(), hangs()},
+			wantErr:   &wantProviderDown,
+		},
+		{
+			name:      "a timeout means the rate may exist",
+			providers: []*stubProvider{fails(domain.ErrRateUnavailable), hangs()},
+			wantErr:   &wantProviderDown,
+		},
+		{
+			name:      "an error means the rate may exist",
+			providers: []*stubProvider{fails(domain.ErrRateUnavailable), fails(errBadResponse)},
+			wantErr:   &wantProviderFailed,
+		},
+		{
+			name:      "an error outweighs a timeout",
+			providers: []*stubProvider{fails(errBadResponse), hangs()},
+			wantErr:   &wantProviderFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched, err := newTestComposite(tt.providers...).FetchRateWithSource(context.Background(), "USD", "EUR", "2024-06-01")
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("fetched = %+v, want an error", fetched)
+				}
+				tt.wantErr.check(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchRateWithSource: %v", err)
+			}
+			if fetched != tt.want {
+				t.Errorf("fetched = %+v, want %+v", fetched, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompositeFetcherFailoverStopsWhenBudgetIsExhausted(t *testing.T) {
+	first, second := hangs(), answers(1.2)
+	composite := newTestComposite(first, second)
+	ctx, cancel := context.WithTimeout(context.Background(), testProviderTimeout/2)
+	defer cancel()
+
+	_, err := composite.FetchRateWithSource(ctx, "USD", "EUR", "2024-06-01")
+	if err == nil {
+		t.Fatal("FetchRateWithSource succeeded after the caller's deadline")
+	}
+	wantProviderDown.check(t, err)
+	if calls := second.calls.Load(); calls != 0 {
+		t.Errorf("next provider called %d times once the caller's deadline passed, want 0", calls)
+	}
+}
+
+func TestCompositeFetcherQuorum(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers []*stubProvider
+		want      domain.FetchedRate
+		wantErr   *wantErrs
+	}{
+		{
+			name:      "providers agree",
+			providers: []*stubProvider{answers(1.000), answers(1.005), answers(0.998)},
+			want:      domain.FetchedRate{Rate: 1.000, Provider: "a"},
+		},
+		{
+			name:      "two of three agree with the median",
+			providers: []*stubProvider{answers(1.000), answers(1.004), answers(2.000)},
+			want:      domain.FetchedRate{Rate: 1.004, Provider: "median(a,b,c)"},
+		},
+		{
+			name:      "two providers disagree beyond tolerance",
+			providers: []*stubProvider{answers(1.0), answers(2.0)},
+			wantErr:   &wantProviderFailed,
+		},
+		{
+			name:      "no two providers agree",
+			providers: []*stubProvider{answers(1.0), answers(2.0), answers(3.0)},
+			wantErr:   &wantProviderFailed,
+		},
+		{
+			name:      "a single answer when the others time out",
+			providers: []*stubProvider{answers(1.0), hangs(), hangs()},
+			wantErr:   &wantProviderDown,
+		},
+		{
+			name:      "a single answer when the others report the rate unavailable",
+			providers: []*stubProvider{fails(domain.ErrRateUnavailable), answers(1.0)},
+			wantErr:   &wantProviderFailed,
+		},
+		{
+			name:      "two answers outvote a failure",
+			providers: []*stubProvider{fails(errBadResponse), answers(1.0), answers(1.001)},
+			want:      domain.FetchedRate{Rate: 1.0, Provider: "b"},
+		},
+		{
+			name:      "every provider reports the rate unavailable",
+			providers: []*stubProvider{fails(domain.ErrRateUnavailable), fails(domain.ErrRateUnavailable)},
+			wantErr:   &wantRateMissing,
+		},
+		{
+			name:      "every provider times out",
+			providers: []*stubProvider{hangs(), hangs()},
+			wantErr:   &wantProviderDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			composite := newTestComposite(tt.providers...).WithQuorum(0.01)
+			fetched, err := composite.FetchRateWithSource(context.Background(), "USD", "EUR", "2024-06-01")
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("fetched = %+v, want an error", fetched)
+				}
+				tt.wantErr.check(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchRateWithSource: %v", err)
+			}
+			if fetched != tt.want {
+				t.Errorf("fetched = %+v, want %+v", fetched, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompositeFetcherBudget(t *testing.T) {
+	providers := []NamedFetcher{
+		{Name: "a", Fetcher: answers(1), Timeout: time.Second},
+		{Name: "b", Fetcher: answers(1), Timeout: 2 * time.Second},
+		{Name: "c", Fetcher: answers(1)},
+	}
+	if got, want := NewCompositeFetcher(providers).Budget(), 3*time.Second+defaultProviderTimeout; got != want {
+		t.Errorf("failover Budget() = %v, want the sum of the timeouts %v", got, want)
+	}
+	if got, want := NewCompositeFetcher(providers).WithQuorum(0.01).Budget(), defaultProviderTimeout; got != want {
+		t.Errorf("quorum Budget() = %v, want the longest timeout %v", got, want)
+	}
+}
+
+func TestMedianRate(t *testing.T) {
+	tests := []struct {
+		rates []float64
+		want  float64
+	}{
+		{[]float64{2}, 2},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+	for _, tt := range tests {
+		if got := medianRate(results(tt.rates...)); got != tt.want {
+			t.Errorf("medianRate(%v) = %v, want %v", tt.rates, got, tt.want)
+		}
+	}
+}
+
+func TestWithinTolerance(t *testing.T) {
+	tests := []struct {
+		rates     []float64
+		median    float64
+		tolerance float64
+		want      bool
+	}{
+		{[]float64{100, 100.5}, 100, 0.005, true},
+		{[]float64{100, 100.6}, 100, 0.005, false},
+		{[]float64{99.5, 100}, 100, 0.005, true},
+		{[]float64{100, 100}, 100, 0, true},
+	}
+	for _, tt := range tests {
+		if got := withinTolerance(results(tt.rates...), tt.median, tt.tolerance); got != tt.want {
+			t.Errorf("withinTolerance(%v, %v, %v) = %v, want %v", tt.rates, tt.median, tt.tolerance, got, tt.want)
+		}
+	}
+}
+
+func results(rates ...float64) []providerResult {
+	res := make([]providerResult, len(rates))
+	for i, rate := range rates {
+		res[i] = providerResult{rate: rate}
+	}
+	return res
+}
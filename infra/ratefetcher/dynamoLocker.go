@@ -2,53 +2,189 @@ package infra
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"time"
 
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	pkg "github.com/ItsDee25/exchange-rate-service/pkg/awsclient"
 	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+// DynamoLocker is a lease based distributed lock stored as a single item per lock key.
+// The item keeps a fence counter that is incremented on every acquisition and never deleted,
+// so fencing tokens stay monotonic across releases.
 type DynamoLocker struct {
 	tableName string
-	client    *dynamodb.Client
+	client    pkg.DynamoAPI
+	owner     string
 }
 
-func NewDynamoLocker(client *dynamodb.Client) *DynamoLocker {
+func NewDynamoLocker(client pkg.DynamoAPI) *DynamoLocker {
 	return &DynamoLocker{
 		tableName: constants.TableName,
 		client:    client,
+		owner:     newOwnerID(),
 	}
 }
 
-func (l *DynamoLocker) AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
-	expiresAt := time.Now().Add(ttl).Unix()
+// newOwnerID identifies this process as a lock owner: hostname plus a random suffix
+func newOwnerID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", host, time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%s-%s", host, hex.EncodeToString(suffix))
+}
+
+func (l *DynamoLocker) WithTableName(tableName string) *DynamoLocker {
+	l.tableName = tableName
+	return l
+}
+
+func (l *DynamoLocker) Owner() string {
+	return l.owner
+}
 
-	item := map[string]types.AttributeValue{
+func lockKey(key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
 		constants.PartitionKey: &types.AttributeValueMemberS{Value: key},
-		constants.ExpiresAt:    &types.AttributeValueMemberN{Value: string(expiresAt)},
+		constants.SortKey:      &types.AttributeValueMemberS{Value: constants.LockSortKey},
+	}
+}
+
+func numberValue(n int64) *types.AttributeValueMemberN {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
+
+// AcquireLock takes the lock if it is free, expired or already held by this owner, and bumps its fencing token.
+func (l *DynamoLocker) AcquireLock(ctx context.Context, key string, ttl time.Duration) (domain.Lease, bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	out, err := l.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(l.tableName),
+		Key:                 lockKey(key),
+		UpdateExpression:    aws.String("SET #owner = :owner, #expires = :expires ADD #fence :one"),
+		ConditionExpression: aws.String("attribute_not_exists(#pk) OR #expires < :now OR #owner = :owner"),
+		ExpressionAttributeNames: map[string]string{
+			"#pk":      constants.PartitionKey,
+			"#owner":   constants.Owner,
+			"#expires": constants.ExpiresAt,
+			"#fence":   constants.Fence,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner":   &types.AttributeValueMemberS{Value: l.owner},
+			":expires": numberValue(expiresAt.Unix()),
+			":now":     numberValue(now.Unix()),
+			":one":     numberValue(1),
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return domain.Lease{}, false, nil // Lock not acquired
+		}
+		return domain.Lease{}, false, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+
+	token, err := fenceFromItem(out.Attributes)
+	if err != nil {
+		return domain.Lease{}, false, fmt.Errorf("failed to acquire lock: %w", err)
 	}
 
-	condExpr := "attribute_not_exists(pk) OR expires_at < :now"
+	return domain.Lease{
+		FencingToken: domain.FencingToken{
+			LockKey: key,
+			Owner:   l.owner,
+			Token:   token,
+		},
+		ExpiresAt: expiresAt,
+	}, true, nil
+}
+
+// Renew extends the lease if this owner still holds it with the same fencing token.
+func (l *DynamoLocker) Renew(ctx context.Context, lease domain.Lease, ttl time.Duration) (domain.Lease, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
 
-	_, err := l.client.PutItem(ctx, &dynamodb.PutItemInput{
+	_, err := l.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
 		TableName:           aws.String(l.tableName),
-		Item:                item,
-		ConditionExpression: aws.String(condExpr),
+		Key:                 lockKey(lease.LockKey),
+		UpdateExpression:    aws.String("SET #expires = :expires"),
+		ConditionExpression: aws.String("#owner = :owner AND #fence = :fence AND #expires >= :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#owner":   constants.Owner,
+			"#expires": constants.ExpiresAt,
+			"#fence":   constants.Fence,
+		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":now": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", time.Now().Unix())},
+			":owner":   &types.AttributeValueMemberS{Value: lease.Owner},
+			":fence":   numberValue(lease.Token),
+			":expires": numberValue(expiresAt.Unix()),
+			":now":     numberValue(now.Unix()),
 		},
 	})
 	if err != nil {
 		var cce *types.ConditionalCheckFailedException
 		if errors.As(err, &cce) {
-			return false, nil // Lock not acquired
+			return domain.Lease{}, domain.ErrLockLost
 		}
-		return false, fmt.Errorf("failed to acquire lock: %w", err)
+		return domain.Lease{}, fmt.Errorf("failed to renew lock: %w", err)
 	}
 
-	return true, nil
+	lease.ExpiresAt = expiresAt
+	return lease, nil
+}
+
+// Release frees the lock if this owner still holds it. The item is kept so the fence counter survives.
+func (l *DynamoLocker) Release(ctx context.Context, lease domain.Lease) error {
+	_, err := l.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(l.tableName),
+		Key:                 lockKey(lease.LockKey),
+		UpdateExpression:    aws.String("SET #expires = :zero REMOVE #owner"),
+		ConditionExpression: aws.String("#owner = :owner AND #fence = :fence"),
+		ExpressionAttributeNames: map[string]string{
+			"#owner":   constants.Owner,
+			"#expires": constants.ExpiresAt,
+			"#fence":   constants.Fence,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner": &types.AttributeValueMemberS{Value: lease.Owner},
+			":fence": numberValue(lease.Token),
+			":zero":  numberValue(0),
+		},
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return domain.ErrLockLost
+		}
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+func fenceFromItem(item map[string]types.AttributeValue) (int64, error) {
+	fence, ok := item[constants.Fence].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, errors.New("lock item has no fence")
+	}
+	token, err := strconv.ParseInt(fence.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fence %q: %w", fence.Value, err)
+	}
+	return token, nil
 }
@@ -0,0 +1,189 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const testLockKey = "test_lock"
+
+// lockItem returns the stored item of the lock
+func lockItem(t *testing.T, fake *mocks.DynamoFake) map[string]types.AttributeValue {
+	t.Helper()
+	for _, it := range fake.Items() {
+		if pk, ok := it[constants.PartitionKey].(*types.AttributeValueMemberS); ok && pk.Value == testLockKey {
+			return it
+		}
+	}
+	t.Fatal("lock item not stored")
+	return nil
+}
+
+// expireLock moves the lease of the stored lock into the past, as if its holder stopped renewing it
+func expireLock(t *testing.T, fake *mocks.DynamoFake) {
+	t.Helper()
+	if err := fake.ExpireItem(testLockKey, constants.LockSortKey); err != nil {
+		t.Fatalf("ExpireItem: %v", err)
+	}
+}
+
+func mustAcquire(t *testing.T, locker *DynamoLocker, ttl time.Duration) domain.Lease {
+	t.Helper()
+	lease, locked, err := locker.AcquireLock(context.Background(), testLockKey, ttl)
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+	if !locked {
+		t.Fatal("lock not acquired")
+	}
+	return lease
+}
+
+func TestDynamoLockerAcquire(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	locker := NewDynamoLocker(fake)
+
+	lease := mustAcquire(t, locker, time.Minute)
+	if lease.Token != 1 || lease.Owner != locker.Owner() || lease.LockKey != testLockKey {
+		t.Errorf("lease = %+v, want token 1 held by %s", lease, locker.Owner())
+	}
+
+	// expires_at is stored as a decimal number of unix seconds, comparable with the :now of the conditions
+	expires, ok := lockItem(t, fake)[constants.ExpiresAt].(*types.AttributeValueMemberN)
+	if !ok {
+		t.Fatalf("expires_at = %v, want a number", lockItem(t, fake)[constants.ExpiresAt])
+	}
+	epoch, err := strconv.ParseInt(expires.Value, 10, 64)
+	if err != nil {
+		t.Fatalf("expires_at %q is not a decimal: %v", expires.Value, err)
+	}
+	if want := time.Now().Add(time.Minute).Unix(); epoch < want-2 || epoch > want+2 {
+		t.Errorf("expires_at = %d, want about %d", epoch, want)
+	}
+}
+
+func TestDynamoLockerAcquireHeldLock(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	holder := NewDynamoLocker(fake)
+	other := NewDynamoLocker(fake)
+	mustAcquire(t, holder, time.Minute)
+
+	if _, locked, err := other.AcquireLock(context.Background(), testLockKey, time.Minute); err != nil || locked {
+		t.Errorf("other owner acquired a held lock: locked = %v, err = %v", locked, err)
+	}
+
+	// the holder acquiring again keeps the lock with a new token
+	if lease := mustAcquire(t, holder, time.Minute); lease.Token != 2 {
+		t.Errorf("token = %d, want 2", lease.Token)
+	}
+}
+
+func TestDynamoLockerAcquireExpiredLock(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	holder := NewDynamoLocker(fake)
+	other := NewDynamoLocker(fake)
+	mustAcquire(t, holder, time.Minute)
+	expireLock(t, fake)
+
+	lease := mustAcquire(t, other, time.Minute)
+	if lease.Token != 2 || lease.Owner != other.Owner() {
+		t.Errorf("lease = %+v, want token 2 held by %s", lease, other.Owner())
+	}
+}
+
+func TestDynamoLockerRenew(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	locker := NewDynamoLocker(fake)
+	lease := mustAcquire(t, locker, time.Minute)
+
+	renewed, err := locker.Renew(context.Background(), lease, time.Hour)
+	if err != nil {
+		t.Fatalf("Renew: %v", err)
+	}
+	if renewed.Token != lease.Token || !renewed.ExpiresAt.After(lease.ExpiresAt) {
+		t.Errorf("renewed = %+v, want token %d expiring after %s", renewed, lease.Token, lease.ExpiresAt)
+	}
+	expires := lockItem(t, fake)[constants.ExpiresAt].(*types.AttributeValueMemberN)
+	if want := strconv.FormatInt(renewed.ExpiresAt.Unix(), 10); expires.Value != want {
+		t.Errorf("stored expires_at = %s, want %s", expires.Value, want)
+	}
+}
+
+func TestDynamoLockerRenewLostLease(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	holder := NewDynamoLocker(fake)
+	other := NewDynamoLocker(fake)
+	lease := mustAcquire(t, holder, time.Minute)
+	expireLock(t, fake)
+	mustAcquire(t, other, time.Minute)
+
+	if _, err := holder.Renew(context.Background(), lease, time.Minute); !errors.Is(err, domain.ErrLockLost) {
+		t.Errorf("err = %v, want ErrLockLost", err)
+	}
+}
+
+func TestDynamoLockerRenewExpiredLease(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	locker := NewDynamoLocker(fake)
+	lease := mustAcquire(t, locker, time.Minute)
+	expireLock(t, fake)
+
+	// an expired lease may already be taken over, it cannot be extended
+	if _, err := locker.Renew(context.Background(), lease, time.Minute); !errors.Is(err, domain.ErrLockLost) {
+		t.Errorf("err = %v, want ErrLockLost", err)
+	}
+}
+
+func TestDynamoLockerRelease(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	holder := NewDynamoLocker(fake)
+	other := NewDynamoLocker(fake)
+	lease := mustAcquire(t, holder, time.Minute)
+
+	if err := holder.Release(context.Background(), lease); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, ok := lockItem(t, fake)[constants.Owner]; ok {
+		t.Error("owner kept after release")
+	}
+	// the item is kept, so the fencing token keeps growing across releases
+	if lease := mustAcquire(t, other, time.Minute); lease.Token != 2 {
+		t.Errorf("token = %d, want 2", lease.Token)
+	}
+}
+
+func TestDynamoLockerReleaseLostLease(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	holder := NewDynamoLocker(fake)
+	other := NewDynamoLocker(fake)
+	lease := mustAcquire(t, holder, time.Minute)
+	expireLock(t, fake)
+	current := mustAcquire(t, other, time.Minute)
+
+	if err := holder.Release(context.Background(), lease); !errors.Is(err, domain.ErrLockLost) {
+		t.Errorf("err = %v, want ErrLockLost", err)
+	}
+	// the stale release left the new holder's lease alone
+	if _, err := other.Renew(context.Background(), current, time.Minute); err != nil {
+		t.Errorf("Renew after a stale release: %v", err)
+	}
+}
+
+func TestDynamoLockerAcquireError(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	fake.FailNext = errors.New("connection refused")
+	locker := NewDynamoLocker(fake)
+
+	_, locked, err := locker.AcquireLock(context.Background(), testLockKey, time.Minute)
+	if err == nil || locked {
+		t.Errorf("locked = %v, err = %v, want the dynamo error", locked, err)
+	}
+}
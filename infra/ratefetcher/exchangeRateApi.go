@@ -3,38 +3,90 @@ package infra
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"time"
+	"net/url"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-// Ensure it implements usecase.RateFetcher
+const defaultExchangeRateAPIBaseURL = "https://api.exchangerate.host"
+
+// unavailableErrorCodes are the api error codes meaning the rate does not exist, as opposed to
+// auth, quota or server errors: no results, invalid source/target currency, invalid date
+var unavailableErrorCodes = map[int]struct{}{
+	106: {}, 201: {}, 202: {}, 302: {}, 402: {},
+}
+
+// Ensure it implements domain.IRateFetcher
 type ExchangeRateAPI struct {
 	baseURL    string
+	apiKey     string
 	httpClient *http.Client
 }
 
-func NewExchangeRateAPI() *ExchangeRateAPI {
-	return &ExchangeRateAPI{
-		baseURL:    "https://api.exchangerate.host",
-		httpClient: &http.Client{Timeout: 5 * time.Second},
+func NewExchangeRateAPI(baseURL, apiKey string, httpClient *http.Client) (*ExchangeRateAPI, error) {
+	if baseURL == "" {
+		baseURL = defaultExchangeRateAPIBaseURL
+	}
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid exchange rate API base url %q: %w", baseURL, err)
 	}
+	if apiKey == "" {
+		return nil, errors.New("exchange rate API requires an access key")
+	}
+	if httpClient == nil {
+		return nil, errors.New("exchange rate API requires an http client")
+	}
+	return &ExchangeRateAPI{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		httpClient: httpClient,
+	}, nil
+}
+
+type apiError struct {
+	Code int    `json:"code"`
+	Type string `json:"type"`
+	Info string `json:"info"`
 }
 
 type apiResponse struct {
-	Success bool    `json:"success"`
-	Rate    float64 `json:"rate"`
+	Success bool      `json:"success"`
+	Result  float64   `json:"result"`
+	Error   *apiError `json:"error"`
 }
 
-// FetchRate implements RateFetcher
-func (e *ExchangeRateAPI) FetchRate(ctx context.Context, from, to, date string) (float64, error) {
-	url := fmt.Sprintf("%s/%s?from=%s&to=%s", e.baseURL, date, from, to)
+// FetchRate implements IRateFetcher
+func (e *ExchangeRateAPI) FetchRate(ctx context.Context, from, to, date string) (_ float64, err error) {
+	ctx, span := tracing.Start(ctx, "ExchangeRateAPI.FetchRate", append(tracing.RateAttrs(from, to, date), tracing.AttrProvider.String(constants.ProviderExchangeRateAPI))...)
+	defer func() { tracing.End(span, err) }()
 
-	resp, err := e.httpClient.Get(url)
+	query := url.Values{}
+	query.Set("access_key", e.apiKey)
+	query.Set("from", from)
+	query.Set("to", to)
+	query.Set("amount", "1")
+	if date != "" {
+		query.Set("date", date)
+	}
+	endpoint := fmt.Sprintf("%s/convert?%s", e.baseURL, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error building rate API request: %w", err)
+	}
+
+	resp, err := e.httpClient.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("error calling rate API: %w", err)
+		return 0, fmt.Errorf("error calling rate API: %w", redactURLError(err))
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 
 	if resp.StatusCode != http.StatusOK {
 		return 0, fmt.Errorf("rate API returned non-200: %d", resp.StatusCode)
@@ -44,6 +96,35 @@ func (e *ExchangeRateAPI) FetchRate(ctx context.Context, from, to, date string)
 	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
 		return 0, fmt.Errorf("decode error: %w", err)
 	}
+	if !parsed.Success {
+		if parsed.Error != nil {
+			if _, ok := unavailableErrorCodes[parsed.Error.Code]; ok {
+				return 0, fmt.Errorf("rate API error %d (%s): %s: %w", parsed.Error.Code, parsed.Error.Type, parsed.Error.Info, domain.ErrRateUnavailable)
+			}
+			return 0, fmt.Errorf("rate API error %d (%s): %s", parsed.Error.Code, parsed.Error.Type, parsed.Error.Info)
+		}
+		return 0, errors.New("rate API returned unsuccessful response")
+	}
+	if parsed.Result <= 0 {
+		return 0, fmt.Errorf("rate API returned invalid rate %v for %s to %s: %w", parsed.Result, from, to, domain.ErrRateUnavailable)
+	}
+
+	return parsed.Result, nil
+}
 
-	return parsed.Rate, nil
+// redactURLError drops the query of the url reported by a failed request, which carries the access key,
+// so the error can be logged and recorded on spans. The underlying error is kept for errors.Is.
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	redacted := *urlErr
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+		u.RawQuery = ""
+		redacted.URL = u.String()
+	} else {
+		redacted.URL = "<redacted>"
+	}
+	return &redacted
 }
@@ -0,0 +1,177 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+)
+
+const testAccessKey = "test-key"
+
+func newTestExchangeRateAPI(t *testing.T, baseURL, apiKey string) *ExchangeRateAPI {
+	t.Helper()
+	api, err := NewExchangeRateAPI(baseURL, apiKey, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewExchangeRateAPI: %v", err)
+	}
+	return api
+}
+
+func TestExchangeRateAPIFetchRate(t *testing.T) {
+	server := mocks.NewRateProviderServer(testAccessKey, map[string]float64{"USD#INR": 83.12})
+	defer server.Close()
+	api := newTestExchangeRateAPI(t, server.URL, testAccessKey)
+
+	rate, err := api.FetchRate(context.Background(), "USD", "INR", "2024-06-01")
+	if err != nil {
+		t.Fatalf("FetchRate: %v", err)
+	}
+	if rate != 83.12 {
+		t.Errorf("rate = %v, want 83.12", rate)
+	}
+}
+
+func TestExchangeRateAPIFetchRateUnavailable(t *testing.T) {
+	server := mocks.NewRateProviderServer(testAccessKey, map[string]float64{})
+	defer server.Close()
+	api := newTestExchangeRateAPI(t, server.URL, testAccessKey)
+
+	_, err := api.FetchRate(context.Background(), "USD", "XXX", "")
+	if !errors.Is(err, domain.ErrRateUnavailable) {
+		t.Errorf("err = %v, want ErrRateUnavailable", err)
+	}
+}
+
+func TestExchangeRateAPIFetchRateInvalidKey(t *testing.T) {
+	server := mocks.NewRateProviderServer(testAccessKey, map[string]float64{"USD#INR": 83.12})
+	defer server.Close()
+	api := newTestExchangeRateAPI(t, server.URL, "wrong-key")
+
+	_, err := api.FetchRate(context.Background(), "USD", "INR", "")
+	if err == nil {
+		t.Fatal("FetchRate succeeded with an invalid access key")
+	}
+	// an auth error says nothing about the rate, it must not be cached as unavailable
+	if errors.Is(err, domain.ErrRateUnavailable) {
+		t.Errorf("err = %v, must not be ErrRateUnavailable", err)
+	}
+}
+
+func TestExchangeRateAPIFetchRateBadResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr string
+	}{
+		{
+			name: "non-200",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "upstream down", http.StatusBadGateway)
+			},
+			wantErr: "non-200: 502",
+		},
+		{
+			name: "malformed json",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"success": true, "result": `))
+			},
+			wantErr: "decode error",
+		},
+		{
+			name: "unsuccessful without error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"success": false}`))
+			},
+			wantErr: "unsuccessful response",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			api := newTestExchangeRateAPI(t, server.URL, testAccessKey)
+
+			_, err := api.FetchRate(context.Background(), "USD", "INR", "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if errors.Is(err, domain.ErrRateUnavailable) {
+				t.Errorf("err = %v, must not be ErrRateUnavailable", err)
+			}
+		})
+	}
+}
+
+// failingTransport fails every request, as an unreachable provider does
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestExchangeRateAPITransportErrorHidesAccessKey(t *testing.T) {
+	const secret = "very-secret-key"
+	api, err := NewExchangeRateAPI("http://provider.invalid", secret, &http.Client{Transport: failingTransport{}})
+	if err != nil {
+		t.Fatalf("NewExchangeRateAPI: %v", err)
+	}
+
+	_, err = api.FetchRate(context.Background(), "USD", "INR", "2024-06-01")
+	if err == nil {
+		t.Fatal("FetchRate succeeded with a failing transport")
+	}
+	if strings.Contains(err.Error(), secret) {
+		t.Errorf("err = %v, leaks the access key", err)
+	}
+	if !strings.Contains(err.Error(), "http://provider.invalid/convert") || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("err = %v, want the url without its query and the cause", err)
+	}
+}
+
+func TestExchangeRateAPITimeoutHidesAccessKey(t *testing.T) {
+	const secret = "very-secret-key"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	api := newTestExchangeRateAPI(t, server.URL, secret)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := api.FetchRate(ctx, "USD", "INR", "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want it to wrap DeadlineExceeded", err)
+	}
+	if err != nil && strings.Contains(err.Error(), secret) {
+		t.Errorf("err = %v, leaks the access key", err)
+	}
+}
+
+func TestNewExchangeRateAPIValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		apiKey  string
+		client  *http.Client
+		wantErr string
+	}{
+		{name: "missing api key", baseURL: "http://localhost", client: http.DefaultClient, wantErr: "access key"},
+		{name: "invalid base url", baseURL: "not a url", apiKey: testAccessKey, client: http.DefaultClient, wantErr: "base url"},
+		{name: "missing http client", baseURL: "http://localhost", apiKey: testAccessKey, wantErr: "http client"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewExchangeRateAPI(tt.baseURL, tt.apiKey, tt.client)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
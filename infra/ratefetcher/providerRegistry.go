@@ -0,0 +1,124 @@
+package infra
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ItsDee25/exchange-rate-service/internal/config"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+)
+
+// ProviderConfig holds the settings needed to build a rate provider.
+type ProviderConfig struct {
+	Name    string
+	BaseURL string
+	APIKey  string
+	Timeout time.Duration
+}
+
+// ProviderFactory builds a rate fetcher from its config and the shared http client.
+type ProviderFactory func(cfg ProviderConfig, httpClient *http.Client) (domain.IRateFetcher, error)
+
+type ProviderRegistry struct {
+	factories map[string]ProviderFactory
+}
+
+// NewProviderRegistry returns a registry with the built-in third party providers registered.
+func NewProviderRegistry() *ProviderRegistry {
+	r := &ProviderRegistry{
+		factories: map[string]ProviderFactory{},
+	}
+	r.Register(constants.ProviderExchangeRateAPI, func(cfg ProviderConfig, httpClient *http.Client) (domain.IRateFetcher, error) {
+		return NewExchangeRateAPI(cfg.BaseURL, cfg.APIKey, httpClient)
+	})
+	return r
+}
+
+// NewFromConfig builds the configured providers, tried in the order they are listed, failing if one is
+// unknown or misconfigured. Besides the built-in providers it serves the in-memory mock provider.
+func NewFromConfig(cfg config.RateProviderConfig, httpClient *http.Client) (*CompositeFetcher, error) {
+	registry := NewProviderRegistry().
+		Register(constants.ProviderMock, func(cfg ProviderConfig, httpClient *http.Client) (domain.IRateFetcher, error) {
+			return mocks.NewMockRateFetcher(), nil
+		})
+
+	cfgs := make([]ProviderConfig, 0, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		cfgs = append(cfgs, ProviderConfig{
+			Name:    p.Name,
+			BaseURL: p.BaseURL,
+			APIKey:  p.APIKey,
+			Timeout: p.Timeout.Duration(),
+		})
+	}
+	return registry.BuildComposite(cfgs, httpClient, cfg.QuorumTolerance)
+}
+
+func (r *ProviderRegistry) Register(name string, factory ProviderFactory) *ProviderRegistry {
+	r.factories[strings.ToLower(name)] = factory
+	return r
+}
+
+func (r *ProviderRegistry) Names() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build returns the fetcher for the configured provider, failing if the provider is unknown or misconfigured.
+func (r *ProviderRegistry) Build(cfg ProviderConfig, httpClient *http.Client) (domain.IRateFetcher, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.Name))
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown rate provider %q, available providers: %s", cfg.Name, strings.Join(r.Names(), ", "))
+	}
+	fetcher, err := factory(cfg, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("rate provider %q misconfigured: %w", name, err)
+	}
+	return fetcher, nil
+}
+
+// BuildComposite builds every configured provider, in priority order, behind a CompositeFetcher.
+// Quorum mode is enabled when quorumTolerance is positive, and needs at least minQuorum providers.
+func (r *ProviderRegistry) BuildComposite(cfgs []ProviderConfig, httpClient *http.Client, quorumTolerance float64) (*CompositeFetcher, error) {
+	if len(cfgs) == 0 {
+		return nil, fmt.Errorf("no rate providers configured")
+	}
+	if quorumTolerance > 0 && len(cfgs) < minQuorum {
+		return nil, fmt.Errorf("quorum mode needs at least %d rate providers, %d configured", minQuorum, len(cfgs))
+	}
+	providers := make([]NamedFetcher, 0, len(cfgs))
+	seen := map[string]struct{}{}
+	for _, cfg := range cfgs {
+		name := strings.ToLower(strings.TrimSpace(cfg.Name))
+		if _, dup := seen[name]; dup {
+			return nil, fmt.Errorf("rate provider %q configured more than once", name)
+		}
+		seen[name] = struct{}{}
+
+		fetcher, err := r.Build(cfg, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, NamedFetcher{
+			Name:    name,
+			Fetcher: fetcher,
+			Timeout: cfg.Timeout,
+		})
+	}
+
+	composite := NewCompositeFetcher(providers)
+	if quorumTolerance > 0 {
+		composite.WithQuorum(quorumTolerance)
+	}
+	return composite, nil
+}
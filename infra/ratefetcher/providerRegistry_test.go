@@ -0,0 +1,120 @@
+package infra
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ItsDee25/exchange-rate-service/internal/config"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	server := mocks.NewRateProviderServer(testAccessKey, map[string]float64{"USD#INR": 83.12})
+	defer server.Close()
+
+	fetcher, err := NewFromConfig(config.RateProviderConfig{
+		Providers: []config.ProviderSettings{{Name: constants.ProviderExchangeRateAPI, BaseURL: server.URL, APIKey: testAccessKey}},
+	}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewFromConfig: %v", err)
+	}
+
+	fetched, err := fetcher.FetchRateWithSource(context.Background(), "USD", "INR", "2024-06-01")
+	if err != nil {
+		t.Fatalf("FetchRateWithSource: %v", err)
+	}
+	if fetched.Rate != 83.12 || fetched.Provider != constants.ProviderExchangeRateAPI {
+		t.Errorf("fetched = %+v, want 83.12 from %s", fetched, constants.ProviderExchangeRateAPI)
+	}
+}
+
+func TestNewFromConfigMockProvider(t *testing.T) {
+	fetcher, err := NewFromConfig(config.RateProviderConfig{
+		Providers: []config.ProviderSettings{{Name: constants.ProviderMock}},
+	}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("NewFromConfig: %v", err)
+	}
+	if _, err := fetcher.FetchRate(context.Background(), "USD", "INR", ""); err != nil {
+		t.Errorf("FetchRate: %v", err)
+	}
+}
+
+func TestNewFromConfigFailsFast(t *testing.T) {
+	tests := []struct {
+		name            string
+		providers       []config.ProviderSettings
+		quorumTolerance float64
+		wantErr         string
+	}{
+		{
+			name:      "unknown provider",
+			providers: []config.ProviderSettings{{Name: "nosuchprovider"}},
+			wantErr:   `unknown rate provider "nosuchprovider"`,
+		},
+		{
+			name:      "missing api key",
+			providers: []config.ProviderSettings{{Name: constants.ProviderExchangeRateAPI}},
+			wantErr:   "access key",
+		},
+		{
+			name:      "listed twice",
+			providers: []config.ProviderSettings{{Name: constants.ProviderMock}, {Name: "MOCK"}},
+			wantErr:   "configured more than once",
+		},
+		{
+			name:    "no provider",
+			wantErr: "no rate providers configured",
+		},
+		{
+			name:            "quorum of one provider",
+			providers:       []config.ProviderSettings{{Name: constants.ProviderMock}},
+			quorumTolerance: 0.01,
+			wantErr:         "quorum mode needs at least 2 rate providers",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher, err := NewFromConfig(config.RateProviderConfig{Providers: tt.providers, QuorumTolerance: tt.quorumTolerance}, http.DefaultClient)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if fetcher != nil {
+				t.Error("a fetcher was returned along with the error")
+			}
+		})
+	}
+}
+
+func TestProviderRegistryUnknownNameDoesNotBuildOthers(t *testing.T) {
+	var built atomic.Int32
+	registry := NewProviderRegistry().Register("counting", func(cfg ProviderConfig, httpClient *http.Client) (domain.IRateFetcher, error) {
+		built.Add(1)
+		return mocks.NewMockRateFetcher(), nil
+	})
+
+	_, err := registry.BuildComposite([]ProviderConfig{{Name: "unknown"}, {Name: "counting"}}, http.DefaultClient, 0)
+	if err == nil {
+		t.Fatal("BuildComposite succeeded with an unknown provider")
+	}
+	if !strings.Contains(err.Error(), "available providers: counting, "+constants.ProviderExchangeRateAPI) {
+		t.Errorf("err = %v, want it to list the available providers", err)
+	}
+	if built.Load() != 0 {
+		t.Errorf("%d providers built after the unknown one, want 0", built.Load())
+	}
+}
+
+func TestProviderRegistryNamesAreCaseInsensitive(t *testing.T) {
+	registry := NewProviderRegistry().Register("Mock", func(cfg ProviderConfig, httpClient *http.Client) (domain.IRateFetcher, error) {
+		return mocks.NewMockRateFetcher(), nil
+	})
+	if _, err := registry.Build(ProviderConfig{Name: " MOCK "}, http.DefaultClient); err != nil {
+		t.Errorf("Build: %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	currencyconstants "github.com/ItsDee25/exchange-rate-service/internal/constants/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+	"github.com/ItsDee25/exchange-rate-service/pkg/logger"
+)
+
+// Config is the runtime configuration of the server and the backfill command.
+// It is built from Default, then the JSON config file, then environment variables, see Load.
+type Config struct {
+	Server       ServerConfig       `json:"server"`
+	Dynamo       DynamoConfig       `json:"dynamo"`
+	RateProvider RateProviderConfig `json:"rate_provider"`
+	Currency     CurrencyConfig     `json:"currency"`
+	Cache        CacheConfig        `json:"cache"`
+	WriteBehind  WriteBehindConfig  `json:"write_behind"`
+	Jobs         JobsConfig         `json:"jobs"`
+	Log          LogConfig          `json:"log"`
+	Tracing      TracingConfig      `json:"tracing"`
+	Health       HealthConfig       `json:"health"`
+}
+
+type ServerConfig struct {
+	Port int `json:"port"`
+	// ShutdownTimeout bounds the graceful shutdown
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+	// HTTPClientTimeout bounds every outbound http call, e.g. to the rate providers
+	HTTPClientTimeout Duration `json:"http_client_timeout"`
+	// AdminToken is the bearer token of the admin api, which is disabled when it is empty
+	AdminToken string `json:"admin_token"`
+}
+
+type DynamoConfig struct {
+	Region string `json:"region"`
+	// Endpoint overrides the AWS endpoint, e.g. http://dynamodb-local:8000
+	Endpoint string `json:"endpoint"`
+	Table    string `json:"table"`
+}
+
+type RateProviderConfig struct {
+	// Providers are tried in order, see infra.CompositeFetcher
+	Providers []ProviderSettings `json:"providers"`
+	// QuorumTolerance enables quorum mode when positive
+	QuorumTolerance float64 `json:"quorum_tolerance"`
+}
+
+type ProviderSettings struct {
+	Name    string   `json:"name"`
+	BaseURL string   `json:"base_url"`
+	APIKey  string   `json:"api_key"`
+	Timeout Duration `json:"timeout"`
+}
+
+type CurrencyConfig struct {
+	// Supported seeds the currency registry: currencies not registered yet are added enabled on startup,
+	// after that the registry is managed through the admin api
+	Supported []string `json:"supported"`
+	// RetentionDays is how far back rates are served and stored
+	RetentionDays       int      `json:"retention_days"`
+	TriangulationPivots []string `json:"triangulation_pivots"`
+	RoundingMode        string   `json:"rounding_mode"`
+}
+
+type CacheConfig struct {
+	MaxEntries    int      `json:"max_entries"`
+	CurrentTTL    Duration `json:"current_ttl"`
+	HistoricalTTL Duration `json:"historical_ttl"`
+	NegativeTTL   Duration `json:"negative_ttl"`
+	// NegativeMaxEntries bounds the negative entries, kept apart so they cannot evict rates
+	NegativeMaxEntries int `json:"negative_max_entries"`
+	// WarmupDays is how many days before today are loaded into the cache on startup, besides today
+	WarmupDays int `json:"warmup_days"`
+	// WarmupTimeout bounds the startup warm-up, the instance is not ready before it finishes
+	WarmupTimeout Duration `json:"warmup_timeout"`
+}
+
+type WriteBehindConfig struct {
+	Capacity      int      `json:"capacity"`
+	Workers       int      `json:"workers"`
+	FlushInterval Duration `json:"flush_interval"`
+	MaxAttempts   int      `json:"max_attempts"`
+}
+
+type JobsConfig struct {
+	RefreshInterval Duration `json:"refresh_interval"`
+	RefreshLockTTL  Duration `json:"refresh_lock_ttl"`
+	// RefreshConcurrency bounds the provider calls a refresher run makes at once
+	RefreshConcurrency int      `json:"refresh_concurrency"`
+	CacheCleanInterval Duration `json:"cache_clean_interval"`
+	// RegistryReloadInterval is how soon currency registry changes made on another instance are picked up
+	RegistryReloadInterval Duration `json:"registry_reload_interval"`
+}
+
+type LogConfig struct {
+	// Level is the lowest level logged: debug, info, warn or error
+	Level string `json:"level"`
+	// Format is json or text
+	Format string `json:"format"`
+}
+
+type TracingConfig struct {
+	// Exporter is none (spans are not recorded), stdout, file or otlp
+	Exporter string `json:"exporter"`
+	// File is the file the file exporter appends spans to
+	File string `json:"file"`
+	// OTLPEndpoint is the OTLP/HTTP collector url, e.g. http://otel-collector:4318
+	OTLPEndpoint string `json:"otlp_endpoint"`
+	// SampleRatio is the fraction of traces recorded, between 0 and 1
+	SampleRatio float64 `json:"sample_ratio"`
+	ServiceName string  `json:"service_name"`
+}
+
+type HealthConfig struct {
+	// CheckTimeout bounds each readiness check
+	CheckTimeout Duration `json:"check_timeout"`
+	// RefresherMaxAge is how old the last successful refresher run may get before the instance is not ready
+	RefresherMaxAge Duration `json:"refresher_max_age"`
+	// ProviderCheckInterval is how often readiness probes call the rate provider, the result is reused in between
+	ProviderCheckInterval Duration `json:"provider_check_interval"`
+}
+
+// Default returns the configuration used when neither a file nor the environment set a value
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:              8080,
+			ShutdownTimeout:   Duration(30 * time.Second),
+			HTTPClientTimeout: Duration(10 * time.Second),
+		},
+		Dynamo: DynamoConfig{
+			Region: "us-west-2",
+			Table:  constants.TableName,
+		},
+		RateProvider: RateProviderConfig{
+			Providers: []ProviderSettings{{Name: constants.DefaultRateProvider}},
+		},
+		Currency: CurrencyConfig{
+			Supported:           append([]string(nil), currencyconstants.DefaultCurrencies...),
+			RetentionDays:       90,
+			TriangulationPivots: splitList(constants.DefaultTriangulationPivots),
+			RoundingMode:        constants.DefaultConversionRoundingMode,
+		},
+		Cache: CacheConfig{
+			MaxEntries:         10000,
+			CurrentTTL:         Duration(2 * time.Hour),
+			HistoricalTTL:      Duration(24 * time.Hour),
+			NegativeTTL:        Duration(5 * time.Minute),
+			NegativeMaxEntries: 1000,
+			WarmupDays:         7,
+			WarmupTimeout:      Duration(time.Minute),
+		},
+		WriteBehind: WriteBehindConfig{
+			Capacity:      1000,
+			Workers:       2,
+			FlushInterval: Duration(500 * time.Millisecond),
+			MaxAttempts:   5,
+		},
+		Jobs: JobsConfig{
+			RefreshInterval:        Duration(30 * time.Minute),
+			RefreshLockTTL:         Duration(2 * time.Minute),
+			RefreshConcurrency:     5,
+			CacheCleanInterval:     Duration(24 * time.Hour),
+			RegistryReloadInterval: Duration(time.Minute),
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: logger.FormatJSON,
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			File:        "traces.jsonl",
+			SampleRatio: 1,
+			ServiceName: "exchange-rate-service",
+		},
+		Health: HealthConfig{
+			CheckTimeout:          Duration(2 * time.Second),
+			RefresherMaxAge:       Duration(time.Hour),
+			ProviderCheckInterval: Duration(5 * time.Minute),
+		},
+	}
+}
+
+// Addr is the address the http server listens on
+func (c ServerConfig) Addr() string {
+	return fmt.Sprintf(":%d", c.Port)
+}
+
+// Retention is RetentionDays as a duration
+func (c CurrencyConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// ParsePairs parses FROM-TO pairs such as USD-INR
+func ParsePairs(raw []string) ([][2]string, error) {
+	pairs := make([][2]string, 0, len(raw))
+	for _, p := range raw {
+		parts := strings.Split(strings.TrimSpace(p), "-")
+		if len(parts) != 2 || !isCurrencyCode(parts[0]) || !isCurrencyCode(parts[1]) {
+			return nil, fmt.Errorf("invalid pair %q, expected FROM-TO", p)
+		}
+		pairs = append(pairs, [2]string{strings.ToUpper(parts[0]), strings.ToUpper(parts[1])})
+	}
+	return pairs, nil
+}
+
+// Duration is a time.Duration written as a Go duration string ("30s", "2h") in the config file
+type Duration time.Duration
+
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
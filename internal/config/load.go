@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+)
+
+// Load builds the configuration: defaults, overridden by the JSON file at path (or CONFIG_FILE when path is empty),
+// overridden by environment variables. The result is validated, every invalid setting is reported.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path == "" {
+		path = os.Getenv(constants.EnvConfigFile)
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+	envErr := cfg.applyEnv()
+	cfg.normalize()
+	if err := errors.Join(envErr, cfg.Validate()); err != nil {
+		return Config{}, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// a misspelled setting would otherwise be silently ignored
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// envReader applies environment overrides, collecting the values that failed to parse
+type envReader struct {
+	errs []error
+}
+
+// lookup returns the first non empty variable among keys
+func (r *envReader) lookup(keys ...string) (string, string, bool) {
+	for _, key := range keys {
+		if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+			return key, v, true
+		}
+	}
+	return "", "", false
+}
+
+func (r *envReader) string(dst *string, keys ...string) {
+	if _, v, ok := r.lookup(keys...); ok {
+		*dst = v
+	}
+}
+
+func (r *envReader) int(dst *int, keys ...string) {
+	if key, v, ok := r.lookup(keys...); ok {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("invalid %s %q", key, v))
+			return
+		}
+		*dst = parsed
+	}
+}
+
+func (r *envReader) float(dst *float64, keys ...string) {
+	if key, v, ok := r.lookup(keys...); ok {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("invalid %s %q", key, v))
+			return
+		}
+		*dst = parsed
+	}
+}
+
+func (r *envReader) duration(dst *Duration, keys ...string) {
+	if key, v, ok := r.lookup(keys...); ok {
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("invalid %s %q", key, v))
+			return
+		}
+		*dst = Duration(parsed)
+	}
+}
+
+func (r *envReader) list(dst *[]string, key string) {
+	if _, v, ok := r.lookup(key); ok {
+		*dst = splitList(v)
+	}
+}
+
+func (c *Config) applyEnv() error {
+	r := &envReader{}
+
+	r.int(&c.Server.Port, constants.EnvServerPort)
+	r.duration(&c.Server.ShutdownTimeout, constants.EnvShutdownTimeout)
+	r.duration(&c.Server.HTTPClientTimeout, constants.EnvHTTPClientTimeout)
+	r.string(&c.Server.AdminToken, constants.EnvAdminToken)
+
+	r.string(&c.Dynamo.Region, constants.EnvDynamoRegion)
+	r.string(&c.Dynamo.Endpoint, constants.EnvDynamoEndpoint)
+	r.string(&c.Dynamo.Table, constants.EnvDynamoTable)
+
+	c.applyProviderEnv(r)
+
+	r.list(&c.Currency.Supported, constants.EnvSupportedCurrencies)
+	r.int(&c.Currency.RetentionDays, constants.EnvRetentionDays)
+	// an empty TRIANGULATION_PIVOTS disables triangulation
+	if raw, ok := os.LookupEnv(constants.EnvTriangulationPivots); ok {
+		c.Currency.TriangulationPivots = splitList(raw)
+	}
+	r.string(&c.Currency.RoundingMode, constants.EnvConversionRoundingMode)
+
+	r.int(&c.Cache.MaxEntries, constants.EnvRateCacheMaxEntries)
+	r.duration(&c.Cache.CurrentTTL, constants.EnvRateCacheCurrentTTL)
+	r.duration(&c.Cache.HistoricalTTL, constants.EnvRateCacheHistoricalTTL)
+	r.duration(&c.Cache.NegativeTTL, constants.EnvRateCacheNegativeTTL)
+	r.int(&c.Cache.NegativeMaxEntries, constants.EnvRateCacheNegativeMaxEntries)
+	r.int(&c.Cache.WarmupDays, constants.EnvRateCacheWarmupDays)
+	r.duration(&c.Cache.WarmupTimeout, constants.EnvRateCacheWarmupTimeout)
+
+	r.int(&c.WriteBehind.Capacity, constants.EnvWriteBehindCapacity)
+	r.int(&c.WriteBehind.Workers, constants.EnvWriteBehindWorkers)
+	r.duration(&c.WriteBehind.FlushInterval, constants.EnvWriteBehindFlushInterval)
+	r.int(&c.WriteBehind.MaxAttempts, constants.EnvWriteBehindMaxAttempts)
+
+	r.duration(&c.Jobs.RefreshInterval, constants.EnvRefresherFreq)
+	r.duration(&c.Jobs.RefreshLockTTL, constants.EnvRefresherLock)
+	r.int(&c.Jobs.RefreshConcurrency, constants.EnvRefresherConcurrency)
+	r.duration(&c.Jobs.CacheCleanInterval, constants.EnvCleanerFreq)
+	r.duration(&c.Jobs.RegistryReloadInterval, constants.EnvRegistryReloadFreq)
+
+	r.string(&c.Log.Level, constants.EnvLogLevel)
+	r.string(&c.Log.Format, constants.EnvLogFormat)
+
+	r.string(&c.Tracing.Exporter, constants.EnvTracingExporter)
+	r.string(&c.Tracing.File, constants.EnvTracingFile)
+	r.string(&c.Tracing.OTLPEndpoint, constants.EnvTracingEndpoint)
+	r.float(&c.Tracing.SampleRatio, constants.EnvTracingSampleRatio)
+
+	r.duration(&c.Health.CheckTimeout, constants.EnvHealthCheckTimeout)
+	r.duration(&c.Health.RefresherMaxAge, constants.EnvHealthRefresherMaxAge)
+	r.duration(&c.Health.ProviderCheckInterval, constants.EnvHealthProviderCheckInterval)
+
+	return errors.Join(r.errs...)
+}
+
+// applyProviderEnv replaces the provider list with RATE_PROVIDER (comma separated, in priority order),
+// keeping the file settings of the providers it names. Every setting can be scoped to one provider,
+// e.g. RATE_PROVIDER_EXCHANGERATEAPI_API_KEY, which wins over the unscoped RATE_PROVIDER_API_KEY.
+func (c *Config) applyProviderEnv(r *envReader) {
+	if _, names, ok := r.lookup(constants.EnvRateProvider); ok {
+		existing := make(map[string]ProviderSettings, len(c.RateProvider.Providers))
+		for _, p := range c.RateProvider.Providers {
+			existing[p.Name] = p
+		}
+		providers := make([]ProviderSettings, 0)
+		for _, name := range splitList(names) {
+			p, ok := existing[name]
+			if !ok {
+				p = ProviderSettings{Name: name}
+			}
+			providers = append(providers, p)
+		}
+		c.RateProvider.Providers = providers
+	}
+
+	for i := range c.RateProvider.Providers {
+		p := &c.RateProvider.Providers[i]
+		r.string(&p.BaseURL, providerEnvKey(p.Name, constants.EnvRateProviderBaseURL), constants.EnvRateProviderBaseURL)
+		r.string(&p.APIKey, providerEnvKey(p.Name, constants.EnvRateProviderAPIKey), constants.EnvRateProviderAPIKey)
+		r.duration(&p.Timeout, providerEnvKey(p.Name, constants.EnvRateProviderTimeout), constants.EnvRateProviderTimeout)
+	}
+	r.float(&c.RateProvider.QuorumTolerance, constants.EnvRateProviderQuorumTolerance)
+}
+
+// providerEnvKey scopes a RATE_PROVIDER_* key to a provider: RATE_PROVIDER_<NAME>_*
+func providerEnvKey(name, key string) string {
+	suffix := strings.TrimPrefix(key, constants.EnvRateProvider+"_")
+	return fmt.Sprintf("%s_%s_%s", constants.EnvRateProvider, strings.ToUpper(name), suffix)
+}
+
+func (c *Config) normalize() {
+	for i, code := range c.Currency.Supported {
+		c.Currency.Supported[i] = strings.ToUpper(strings.TrimSpace(code))
+	}
+	for i, code := range c.Currency.TriangulationPivots {
+		c.Currency.TriangulationPivots[i] = strings.ToUpper(strings.TrimSpace(code))
+	}
+	c.Currency.RoundingMode = strings.ToLower(strings.TrimSpace(c.Currency.RoundingMode))
+	c.Log.Level = strings.ToLower(strings.TrimSpace(c.Log.Level))
+	c.Log.Format = strings.ToLower(strings.TrimSpace(c.Log.Format))
+	c.Tracing.Exporter = strings.ToLower(strings.TrimSpace(c.Tracing.Exporter))
+}
+
+func splitList(raw string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+)
+
+// writeConfigFile writes content to a config file in a temporary directory and returns its path
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv(constants.EnvConfigFile, "")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != Default().Server.Port || cfg.Jobs.RefreshInterval != Default().Jobs.RefreshInterval {
+		t.Errorf("cfg = %+v, want the defaults", cfg)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"server": {"port": 9090, "shutdown_timeout": "10s"},
+		"currency": {"retention_days": 30},
+		"jobs": {"refresh_interval": "10m"}
+	}`)
+	t.Setenv(constants.EnvServerPort, "7070")
+	t.Setenv(constants.EnvRefresherFreq, "5m")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	// env wins over the file
+	if cfg.Server.Port != 7070 {
+		t.Errorf("port = %d, want 7070 from the environment", cfg.Server.Port)
+	}
+	if cfg.Jobs.RefreshInterval.Duration() != 5*time.Minute {
+		t.Errorf("refresh_interval = %s, want 5m from the environment", cfg.Jobs.RefreshInterval.Duration())
+	}
+	// the file wins over the defaults
+	if cfg.Server.ShutdownTimeout.Duration() != 10*time.Second {
+		t.Errorf("shutdown_timeout = %s, want 10s from the file", cfg.Server.ShutdownTimeout.Duration())
+	}
+	if cfg.Currency.RetentionDays != 30 {
+		t.Errorf("retention_days = %d, want 30 from the file", cfg.Currency.RetentionDays)
+	}
+	// settings set nowhere keep their defaults
+	if cfg.Cache.MaxEntries != Default().Cache.MaxEntries {
+		t.Errorf("max_entries = %d, want the default %d", cfg.Cache.MaxEntries, Default().Cache.MaxEntries)
+	}
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	t.Setenv(constants.EnvConfigFile, writeConfigFile(t, `{"server": {"port": 9090}}`))
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Errorf("port = %d, want 9090 from %s", cfg.Server.Port, constants.EnvConfigFile)
+	}
+}
+
+func TestLoadScopedProviderEnv(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"rate_provider": {"providers": [
+			{"name": "mock", "timeout": "3s"},
+			{"name": "exchangerateapi", "base_url": "http://file.example"}
+		]}
+	}`)
+	t.Setenv(constants.EnvRateProvider, "exchangerateapi,mock")
+	t.Setenv(constants.EnvRateProviderAPIKey, "shared-key")
+	t.Setenv("RATE_PROVIDER_EXCHANGERATEAPI_API_KEY", "scoped-key")
+	t.Setenv("RATE_PROVIDER_MOCK_TIMEOUT", "7s")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	providers := cfg.RateProvider.Providers
+	if len(providers) != 2 || providers[0].Name != "exchangerateapi" || providers[1].Name != "mock" {
+		t.Fatalf("providers = %+v, want exchangerateapi then mock", providers)
+	}
+	// the scoped key wins over the unscoped one, which still applies to the other providers
+	if providers[0].APIKey != "scoped-key" || providers[1].APIKey != "shared-key" {
+		t.Errorf("api keys = %q, %q, want scoped-key, shared-key", providers[0].APIKey, providers[1].APIKey)
+	}
+	// RATE_PROVIDER reorders the providers, keeping their file settings
+	if providers[0].BaseURL != "http://file.example" {
+		t.Errorf("base_url = %q, want the file setting", providers[0].BaseURL)
+	}
+	if providers[1].Timeout.Duration() != 7*time.Second {
+		t.Errorf("mock timeout = %s, want 7s", providers[1].Timeout.Duration())
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	path := writeConfigFile(t, `{"server": {"prot": 9090}}`)
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "prot"`) {
+		t.Errorf("err = %v, want an unknown field error", err)
+	}
+}
+
+func TestLoadReportsEveryInvalidSetting(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"server": {"port": 70000},
+		"jobs": {"refresh_lock_ttl": "0s"},
+		"log": {"format": "xml"}
+	}`)
+	t.Setenv(constants.EnvRetentionDays, "ninety")
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("Load succeeded with invalid settings")
+	}
+	for _, want := range []string{
+		"server.port 70000 out of range",
+		"jobs.refresh_lock_ttl must be positive",
+		"log.format must be",
+		`invalid RATE_RETENTION_DAYS "ninety"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want it to contain %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
+	"github.com/ItsDee25/exchange-rate-service/pkg/logger"
+)
+
+// Validate reports every invalid setting at once, so a bad deployment fails on startup with the full list
+func (c Config) Validate() error {
+	errs := make([]error, 0)
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port %d out of range", c.Server.Port)
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	check(c.Server.HTTPClientTimeout > 0, "server.http_client_timeout must be positive")
+
+	check(c.Dynamo.Region != "", "dynamo.region is required")
+	check(c.Dynamo.Table != "", "dynamo.table is required")
+
+	check(len(c.RateProvider.Providers) > 0, "at least one rate provider is required")
+	seen := make(map[string]bool, len(c.RateProvider.Providers))
+	for i, p := range c.RateProvider.Providers {
+		check(p.Name != "", "rate_provider.providers[%d].name is required", i)
+		check(!seen[p.Name], "rate provider %q is listed twice", p.Name)
+		check(p.Timeout >= 0, "rate provider %q timeout must not be negative", p.Name)
+		seen[p.Name] = true
+	}
+	check(c.RateProvider.QuorumTolerance >= 0, "rate_provider.quorum_tolerance must not be negative")
+
+	check(len(c.Currency.Supported) > 0, "at least one supported currency is required")
+	for _, code := range c.Currency.Supported {
+		check(isCurrencyCode(code), "invalid supported currency %q", code)
+	}
+	check(c.Currency.RetentionDays >= 1, "currency.retention_days must be at least 1")
+	for _, pivot := range c.Currency.TriangulationPivots {
+		check(isCurrencyCode(pivot), "invalid triangulation pivot %q", pivot)
+	}
+	if _, err := domain.ParseRoundingMode(c.Currency.RoundingMode); err != nil {
+		errs = append(errs, fmt.Errorf("currency.rounding_mode: %w", err))
+	}
+
+	check(c.Cache.MaxEntries > 0, "cache.max_entries must be positive")
+	check(c.Cache.CurrentTTL > 0, "cache.current_ttl must be positive")
+	check(c.Cache.HistoricalTTL > 0, "cache.historical_ttl must be positive")
+	check(c.Cache.NegativeTTL > 0, "cache.negative_ttl must be positive")
+	check(c.Cache.NegativeMaxEntries > 0, "cache.negative_max_entries must be positive")
+	check(c.Cache.WarmupDays >= 0 && c.Cache.WarmupDays < c.Currency.RetentionDays, "cache.warmup_days must be between 0 and currency.retention_days - 1")
+	check(c.Cache.WarmupTimeout > 0, "cache.warmup_timeout must be positive")
+
+	check(c.WriteBehind.Capacity > 0, "write_behind.capacity must be positive")
+	check(c.WriteBehind.Workers > 0, "write_behind.workers must be positive")
+	check(c.WriteBehind.FlushInterval > 0, "write_behind.flush_interval must be positive")
+	check(c.WriteBehind.MaxAttempts > 0, "write_behind.max_attempts must be positive")
+
+	check(c.Jobs.RefreshInterval > 0, "jobs.refresh_interval must be positive")
+	check(c.Jobs.RefreshLockTTL > 0, "jobs.refresh_lock_ttl must be positive")
+	check(c.Jobs.RefreshConcurrency > 0, "jobs.refresh_concurrency must be positive")
+	check(c.Jobs.CacheCleanInterval > 0, "jobs.cache_clean_interval must be positive")
+	check(c.Jobs.RegistryReloadInterval > 0, "jobs.registry_reload_interval must be positive")
+
+	if _, err := logger.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	check(c.Log.Format == logger.FormatJSON || c.Log.Format == logger.FormatText, "log.format must be %s or %s, got %q", logger.FormatJSON, logger.FormatText, c.Log.Format)
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	case tracing.ExporterFile:
+		check(c.Tracing.File != "", "tracing.file is required by the file exporter")
+	default:
+		check(false, "unknown tracing.exporter %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+
+	check(c.Health.CheckTimeout > 0, "health.check_timeout must be positive")
+	check(c.Health.RefresherMaxAge > c.Jobs.RefreshInterval, "health.refresher_max_age must be longer than jobs.refresh_interval")
+	check(c.Health.ProviderCheckInterval > 0, "health.provider_check_interval must be positive")
+
+	return errors.Join(errs...)
+}
+
+// isCurrencyCode reports whether code looks like an ISO 4217 code
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
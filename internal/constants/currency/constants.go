@@ -1,29 +1,13 @@
 package constants
 
-var SupportedCurrencies = map[string]struct{}{
-	"USD": {}, "INR": {}, "EUR": {}, "JPY": {}, "GBP": {},
-}
+// DefaultCurrencies seed the currency registry, see internal/config
+var DefaultCurrencies = []string{"EUR", "GBP", "INR", "JPY", "USD"}
 
-var SupportedCurrencyPairs = [][2]string{
-	{"USD", "INR"},
-	{"USD", "EUR"},
-	{"USD", "GBP"},
-	{"USD", "JPY"},
-	{"USD", "CAD"},
-	{"USD", "AUD"},
-	{"EUR", "INR"},
-	{"EUR", "USD"},
-	{"EUR", "GBP"},
-	{"EUR", "JPY"},
-	{"GBP", "USD"},
-	{"GBP", "INR"},
-	{"GBP", "EUR"},
-	{"INR", "USD"},
-	{"INR", "EUR"},
-	{"INR", "GBP"},
-	{"JPY", "USD"},
-	{"JPY", "EUR"},
-	{"CAD", "USD"},
-	{"AUD", "USD"},
-}
+// MaxBatchConversionItems caps the number of line items in one batch conversion request
+const MaxBatchConversionItems = 100
 
+// TimeSeriesBackfillConcurrency bounds the provider calls made to fill gaps in a time series
+const TimeSeriesBackfillConcurrency = 5
+
+// BatchConversionConcurrency bounds the provider calls made to resolve the rates missing from a batch conversion
+const BatchConversionConcurrency = 5
@@ -0,0 +1,51 @@
+package constants
+
+// ISOCurrency is the ISO 4217 metadata used to register a currency without spelling it out
+type ISOCurrency struct {
+	Name        string
+	NumericCode string
+}
+
+// ISO4217 holds the metadata of commonly traded currencies, minor units come from GetMinorUnits
+var ISO4217 = map[string]ISOCurrency{
+	"AED": {Name: "UAE Dirham", NumericCode: "784"},
+	"ARS": {Name: "Argentine Peso", NumericCode: "032"},
+	"AUD": {Name: "Australian Dollar", NumericCode: "036"},
+	"BHD": {Name: "Bahraini Dinar", NumericCode: "048"},
+	"BRL": {Name: "Brazilian Real", NumericCode: "986"},
+	"CAD": {Name: "Canadian Dollar", NumericCode: "124"},
+	"CHF": {Name: "Swiss Franc", NumericCode: "756"},
+	"CLP": {Name: "Chilean Peso", NumericCode: "152"},
+	"CNY": {Name: "Yuan Renminbi", NumericCode: "156"},
+	"CZK": {Name: "Czech Koruna", NumericCode: "203"},
+	"DKK": {Name: "Danish Krone", NumericCode: "208"},
+	"EGP": {Name: "Egyptian Pound", NumericCode: "818"},
+	"EUR": {Name: "Euro", NumericCode: "978"},
+	"GBP": {Name: "Pound Sterling", NumericCode: "826"},
+	"HKD": {Name: "Hong Kong Dollar", NumericCode: "344"},
+	"HUF": {Name: "Forint", NumericCode: "348"},
+	"IDR": {Name: "Rupiah", NumericCode: "360"},
+	"ILS": {Name: "New Israeli Sheqel", NumericCode: "376"},
+	"INR": {Name: "Indian Rupee", NumericCode: "356"},
+	"JPY": {Name: "Yen", NumericCode: "392"},
+	"KRW": {Name: "Won", NumericCode: "410"},
+	"KWD": {Name: "Kuwaiti Dinar", NumericCode: "414"},
+	"MXN": {Name: "Mexican Peso", NumericCode: "484"},
+	"MYR": {Name: "Malaysian Ringgit", NumericCode: "458"},
+	"NGN": {Name: "Naira", NumericCode: "566"},
+	"NOK": {Name: "Norwegian Krone", NumericCode: "578"},
+	"NZD": {Name: "New Zealand Dollar", NumericCode: "554"},
+	"OMR": {Name: "Rial Omani", NumericCode: "512"},
+	"PHP": {Name: "Philippine Peso", NumericCode: "608"},
+	"PKR": {Name: "Pakistan Rupee", NumericCode: "586"},
+	"PLN": {Name: "Zloty", NumericCode: "985"},
+	"SAR": {Name: "Saudi Riyal", NumericCode: "682"},
+	"SEK": {Name: "Swedish Krona", NumericCode: "752"},
+	"SGD": {Name: "Singapore Dollar", NumericCode: "702"},
+	"THB": {Name: "Baht", NumericCode: "764"},
+	"TRY": {Name: "Turkish Lira", NumericCode: "949"},
+	"TWD": {Name: "New Taiwan Dollar", NumericCode: "901"},
+	"USD": {Name: "US Dollar", NumericCode: "840"},
+	"VND": {Name: "Dong", NumericCode: "704"},
+	"ZAR": {Name: "Rand", NumericCode: "710"},
+}
@@ -0,0 +1,22 @@
+package constants
+
+// DefaultMinorUnits is used for currencies missing from MinorUnits
+const DefaultMinorUnits = 2
+
+// MinorUnits holds the ISO 4217 number of decimal places for currencies that don't use 2
+var MinorUnits = map[string]int32{
+	// zero decimal currencies
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	// three decimal currencies
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	// four decimal currencies
+	"CLF": 4, "UYW": 4,
+}
+
+func GetMinorUnits(code string) int32 {
+	if units, ok := MinorUnits[code]; ok {
+		return units
+	}
+	return DefaultMinorUnits
+}
@@ -0,0 +1,118 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Code identifies the kind of error, clients branch on it rather than on the message
+type Code string
+
+const (
+	CodeInvalidParameter    Code = "invalid_parameter"
+	CodeInvalidBody         Code = "invalid_body"
+	CodeUnauthorized        Code = "unauthorized"
+	CodeNotFound            Code = "not_found"
+	CodeRateUnavailable     Code = "rate_unavailable"
+	CodeProviderFailed      Code = "provider_failed"
+	CodeProviderUnavailable Code = "provider_unavailable"
+	CodeUpstreamTimeout     Code = "upstream_timeout"
+	CodeInternal            Code = "internal_error"
+)
+
+const (
+	rateUnavailableMessage     = "Exchange rate not available for the requested pair and date"
+	providerFailedMessage      = "Rate provider failed to serve the exchange rate"
+	providerUnavailableMessage = "Rate provider did not answer in time, retry later"
+	upstreamTimeoutMessage     = "A dependency did not answer in time, retry later"
+)
+
+// Error is the body of every error response, Field names the invalid parameter or body field when there is one
+type Error struct {
+	Status    int    `json:"-"`
+	Code      Code   `json:"code"`
+	Message   string `json:"message"`
+	Field     string `json:"field,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+// legacyKey marks a request served by a deprecated unversioned route, see UseLegacyShape
+const legacyKey = "apierror.legacy"
+
+// UseLegacyShape makes the errors of the request keep the shape served before versioning, {"error": "message"},
+// with every server side failure reported as a 500
+func UseLegacyShape(c *gin.Context) {
+	c.Set(legacyKey, true)
+}
+
+// IsLegacy reports whether the request answers in the shape served before versioning
+func IsLegacy(c *gin.Context) bool {
+	return c.GetBool(legacyKey)
+}
+
+// Envelope wraps the error of a response: {"error": {"code": ..., "message": ..., "field": ..., "request_id": ...}}
+type Envelope struct {
+	Error *Error `json:"error"`
+}
+
+func New(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// InvalidParameter reports an invalid query, path or body field
+func InvalidParameter(field, message string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeInvalidParameter, Message: message, Field: field}
+}
+
+func InvalidBody(message string) *Error {
+	return New(http.StatusBadRequest, CodeInvalidBody, message)
+}
+
+func Internal(message string) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, message)
+}
+
+// FromError maps the typed domain errors to their status: 404 for a rate or currency that does not exist,
+// 400 for an invalid currency, 502 for a provider failure and 503 for a provider timeout. Any other deadline,
+// e.g. of DynamoDB, is a 504. Any other error is a 500 with the fallback message, its details are logged but
+// not returned.
+func FromError(err error, fallback string) *Error {
+	switch {
+	case errors.Is(err, domain.ErrRateUnavailable):
+		return New(http.StatusNotFound, CodeRateUnavailable, rateUnavailableMessage)
+	case errors.Is(err, domain.ErrInvalidCurrency):
+		return New(http.StatusBadRequest, CodeInvalidParameter, err.Error())
+	case errors.Is(err, domain.ErrProviderFailed):
+		return New(http.StatusBadGateway, CodeProviderFailed, providerFailedMessage)
+	case errors.Is(err, domain.ErrProviderUnavailable):
+		return New(http.StatusServiceUnavailable, CodeProviderUnavailable, providerUnavailableMessage)
+	case errors.Is(err, context.DeadlineExceeded):
+		return New(http.StatusGatewayTimeout, CodeUpstreamTimeout, upstreamTimeoutMessage)
+	default:
+		return Internal(fallback)
+	}
+}
+
+// Abort writes the error envelope, tagged with the request id, and stops the handler chain
+func Abort(c *gin.Context, err *Error) {
+	if IsLegacy(c) {
+		status := err.Status
+		if status >= http.StatusInternalServerError {
+			status = http.StatusInternalServerError
+		}
+		c.AbortWithStatusJSON(status, gin.H{"error": err.Message})
+		return
+	}
+	response := *err
+	response.RequestID = logger.RequestID(c.Request.Context())
+	c.AbortWithStatusJSON(err.Status, Envelope{Error: &response})
+}
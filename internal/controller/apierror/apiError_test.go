@@ -0,0 +1,101 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// abort runs Abort on a test request, in the legacy shape when legacy is set
+func abort(t *testing.T, err *Error, legacy bool) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/currency/convert", nil)
+	if legacy {
+		UseLegacyShape(c)
+	}
+	Abort(c, err)
+	if !c.IsAborted() {
+		t.Error("handler chain was not aborted")
+	}
+	return w
+}
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantCode   Code
+	}{
+		{err: &domain.RateUnavailableError{}, wantStatus: http.StatusNotFound, wantCode: CodeRateUnavailable},
+		{err: fmt.Errorf("%w: XXX", domain.ErrInvalidCurrency), wantStatus: http.StatusBadRequest, wantCode: CodeInvalidParameter},
+		{err: fmt.Errorf("wrapped: %w", domain.ErrProviderFailed), wantStatus: http.StatusBadGateway, wantCode: CodeProviderFailed},
+		{err: domain.ErrProviderUnavailable, wantStatus: http.StatusServiceUnavailable, wantCode: CodeProviderUnavailable},
+		{err: fmt.Errorf("all rate providers failed: %w: %w", domain.ErrProviderUnavailable, context.DeadlineExceeded), wantStatus: http.StatusServiceUnavailable, wantCode: CodeProviderUnavailable},
+		{err: fmt.Errorf("all rate providers failed: %w: %w", domain.ErrProviderFailed, context.DeadlineExceeded), wantStatus: http.StatusBadGateway, wantCode: CodeProviderFailed},
+		{err: fmt.Errorf("GetItem: %w", context.DeadlineExceeded), wantStatus: http.StatusGatewayTimeout, wantCode: CodeUpstreamTimeout},
+		{err: fmt.Errorf("dynamo down"), wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+	for _, tt := range tests {
+		got := FromError(tt.err, "Failed")
+		if got.Status != tt.wantStatus || got.Code != tt.wantCode {
+			t.Errorf("FromError(%v) = %d %s, want %d %s", tt.err, got.Status, got.Code, tt.wantStatus, tt.wantCode)
+		}
+	}
+}
+
+func TestAbortWritesEnvelope(t *testing.T) {
+	w := abort(t, InvalidParameter("amount", "amount must be a positive decimal"), false)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+	var body Envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error == nil {
+		t.Fatalf("body %s is not an error envelope: %v", w.Body, err)
+	}
+	if body.Error.Code != CodeInvalidParameter || body.Error.Field != "amount" {
+		t.Errorf("error = %+v, want invalid_parameter on amount", body.Error)
+	}
+}
+
+func TestAbortKeepsLegacyShape(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        *Error
+		wantStatus int
+	}{
+		{name: "client error", err: InvalidParameter("from", "from must be an enabled currency code"), wantStatus: http.StatusBadRequest},
+		{name: "not found", err: FromError(&domain.RateUnavailableError{}, "Failed"), wantStatus: http.StatusNotFound},
+		{name: "provider failure", err: FromError(domain.ErrProviderFailed, "Failed"), wantStatus: http.StatusInternalServerError},
+		{name: "provider timeout", err: FromError(domain.ErrProviderUnavailable, "Failed"), wantStatus: http.StatusInternalServerError},
+		{name: "upstream timeout", err: FromError(context.DeadlineExceeded, "Failed"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := abort(t, tt.err, true)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var body map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %s is not {\"error\": \"message\"}: %v", w.Body, err)
+			}
+			if len(body) != 1 || body["error"] != tt.err.Message {
+				t.Errorf("body = %v, want only the error message %q", body, tt.err.Message)
+			}
+		})
+	}
+}
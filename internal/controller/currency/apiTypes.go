@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ItsDee25/exchange-rate-service/internal/controller/apierror"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+// convertRequest is the query of GET /v1/currency/convert
+type convertRequest struct {
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Amount string `form:"amount"`
+	Date   string `form:"date"`
+	MaxAge string `form:"max_age"`
+}
+
+// exchangeRateRequest is the query of GET /v1/currency/exchangeRate
+type exchangeRateRequest struct {
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Date   string `form:"date"`
+	MaxAge string `form:"max_age"`
+}
+
+// timeSeriesRequest is the query of GET /v1/currency/timeseries, End defaults to today
+type timeSeriesRequest struct {
+	From  string `form:"from"`
+	To    string `form:"to"`
+	Start string `form:"start"`
+	End   string `form:"end"`
+}
+
+// batchConvertItem takes the amount as a JSON string or number, a number is read without float rounding
+type batchConvertItem struct {
+	From   string          `json:"from"`
+	To     string          `json:"to"`
+	Amount json.RawMessage `json:"amount"`
+	Date   string          `json:"date"`
+}
+
+// batchConvertRequest is the body of POST /v1/currency/convert/batch
+type batchConvertRequest struct {
+	Items []batchConvertItem `json:"items"`
+}
+
+// currencyUpdateRequest is the body of PUT /v1/admin/currencies/:code, omitted fields keep their current value
+type currencyUpdateRequest struct {
+	Name        *string `json:"name"`
+	NumericCode *string `json:"numeric_code"`
+	MinorUnits  *int32  `json:"minor_units"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+type provenanceResponse struct {
+	Provider    string             `json:"provider"`
+	RefreshMode domain.RefreshMode `json:"refresh_mode"`
+	FetchedAt   string             `json:"fetched_at,omitempty"`
+}
+
+// freshness tells when the rate was stored and where it was read from, both omitted when unknown
+type freshness struct {
+	AsOf   string            `json:"as_of,omitempty"`
+	Source domain.RateSource `json:"source,omitempty"`
+}
+
+type legResponse struct {
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Date     string  `json:"date"`
+	Rate     float64 `json:"rate"`
+	Inverted bool    `json:"inverted"`
+	freshness
+	Provenance *provenanceResponse `json:"provenance,omitempty"`
+}
+
+type exchangeRateResponse struct {
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	Date    string            `json:"date"`
+	Rate    float64           `json:"rate"`
+	Method  domain.RateMethod `json:"method"`
+	Derived bool              `json:"derived"`
+	freshness
+	Provenance *provenanceResponse `json:"provenance,omitempty"`
+	Legs       []legResponse       `json:"legs,omitempty"`
+}
+
+type conversionResponse struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	Amount          string  `json:"amount"`
+	Date            string  `json:"date"`
+	Rate            float64 `json:"rate"`
+	Derived         bool    `json:"derived"`
+	ConvertedAmount string  `json:"converted_amount"`
+	freshness
+}
+
+// batchConvertResult is one item of a batch conversion, either converted or failed with Error
+type batchConvertResult struct {
+	Index           int      `json:"index"`
+	From            string   `json:"from"`
+	To              string   `json:"to"`
+	Amount          string   `json:"amount,omitempty"`
+	Date            string   `json:"date"`
+	Rate            *float64 `json:"rate,omitempty"`
+	Derived         *bool    `json:"derived,omitempty"`
+	ConvertedAmount string   `json:"converted_amount,omitempty"`
+	// Error is the error of a failed item, without request id which is the one of the response
+	Error *apierror.Error `json:"error,omitempty"`
+}
+
+type batchConvertResponse struct {
+	Results []batchConvertResult `json:"results"`
+}
+
+// legacyBatchConvertResult is a batchConvertResult as served before versioning, with the error message only
+type legacyBatchConvertResult struct {
+	batchConvertResult
+	Error string `json:"error,omitempty"`
+}
+
+type legacyBatchConvertResponse struct {
+	Results []legacyBatchConvertResult `json:"results"`
+}
+
+type timeSeriesPointResponse struct {
+	Date       string              `json:"date"`
+	Rate       float64             `json:"rate"`
+	Backfilled bool                `json:"backfilled"`
+	Provenance *provenanceResponse `json:"provenance,omitempty"`
+}
+
+type timeSeriesResponse struct {
+	From    string                    `json:"from"`
+	To      string                    `json:"to"`
+	Start   string                    `json:"start"`
+	End     string                    `json:"end"`
+	Rates   []timeSeriesPointResponse `json:"rates"`
+	Missing []string                  `json:"missing"`
+}
+
+type currencyResponse struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	NumericCode string `json:"numeric_code"`
+	MinorUnits  int32  `json:"minor_units"`
+	Enabled     bool   `json:"enabled"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+}
+
+type currenciesResponse struct {
+	Currencies []currencyResponse `json:"currencies"`
+}
+
+func newFreshness(rate domain.RateKey) freshness {
+	f := freshness{Source: rate.Source}
+	if asOf := rate.AsOf(); !asOf.IsZero() {
+		f.AsOf = asOf.UTC().Format(time.RFC3339)
+	}
+	return f
+}
+
+// newProvenanceResponse returns nil for rates without provenance, such as derived rates and identity rates
+func newProvenanceResponse(p domain.Provenance) *provenanceResponse {
+	if p.Provider == "" {
+		return nil
+	}
+	response := &provenanceResponse{
+		Provider:    p.Provider,
+		RefreshMode: p.RefreshMode,
+	}
+	if !p.FetchedAt.IsZero() {
+		response.FetchedAt = p.FetchedAt.UTC().Format(time.RFC3339)
+	}
+	return response
+}
+
+func newExchangeRateResponse(rate domain.ExchangeRate) exchangeRateResponse {
+	response := exchangeRateResponse{
+		From:       rate.From,
+		To:         rate.To,
+		Date:       rate.Date,
+		Rate:       rate.Rate,
+		Method:     rate.Method,
+		Derived:    rate.Derived(),
+		freshness:  newFreshness(rate.Oldest()),
+		Provenance: newProvenanceResponse(rate.Provenance),
+	}
+	if rate.Derived() {
+		response.Legs = make([]legResponse, 0, len(rate.Legs))
+		for _, leg := range rate.Legs {
+			response.Legs = append(response.Legs, legResponse{
+				From:       leg.From,
+				To:         leg.To,
+				Date:       leg.Date,
+				Rate:       leg.Rate,
+				Inverted:   leg.Inverted,
+				freshness:  newFreshness(leg.RateKey),
+				Provenance: newProvenanceResponse(leg.Provenance),
+			})
+		}
+	}
+	return response
+}
+
+func newConversionResponse(conversion domain.Conversion) conversionResponse {
+	return conversionResponse{
+		From:            conversion.Amount.Currency,
+		To:              conversion.Converted.Currency,
+		Amount:          conversion.Amount.Amount.String(),
+		Date:            conversion.ExchangeRate.Date,
+		Rate:            conversion.ExchangeRate.Rate,
+		Derived:         conversion.ExchangeRate.Derived(),
+		ConvertedAmount: conversion.Converted.Amount.String(),
+		freshness:       newFreshness(conversion.ExchangeRate.Oldest()),
+	}
+}
+
+func newLegacyBatchConvertResponse(results []batchConvertResult) legacyBatchConvertResponse {
+	response := legacyBatchConvertResponse{Results: make([]legacyBatchConvertResult, 0, len(results))}
+	for _, result := range results {
+		legacy := legacyBatchConvertResult{batchConvertResult: result}
+		if result.Error != nil {
+			legacy.Error = result.Error.Message
+		}
+		response.Results = append(response.Results, legacy)
+	}
+	return response
+}
+
+func newCurrencyResponse(currency domain.Currency) currencyResponse {
+	response := currencyResponse{
+		Code:        currency.Code,
+		Name:        currency.Name,
+		NumericCode: currency.NumericCode,
+		MinorUnits:  currency.MinorUnits,
+		Enabled:     currency.Enabled,
+	}
+	if !currency.UpdatedAt.IsZero() {
+		response.UpdatedAt = currency.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ItsDee25/exchange-rate-service/internal/controller/apierror"
+)
+
+func TestLegacyBatchConvertResponseHasErrorMessages(t *testing.T) {
+	rate := 83.12
+	results := []batchConvertResult{
+		{Index: 0, From: "USD", To: "INR", Amount: "10", Date: "2024-06-01", Rate: &rate, ConvertedAmount: "831.20"},
+		{Index: 1, From: "USD", To: "XXX", Error: apierror.InvalidParameter("to", "to must be an enabled currency code")},
+	}
+
+	data, err := json.Marshal(newLegacyBatchConvertResponse(results))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var body struct {
+		Results []map[string]any `json:"results"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(body.Results) != 2 {
+		t.Fatalf("results = %s, want 2", data)
+	}
+	if _, ok := body.Results[0]["error"]; ok || body.Results[0]["converted_amount"] != "831.20" {
+		t.Errorf("converted item = %v, want converted_amount 831.20 and no error", body.Results[0])
+	}
+	if body.Results[1]["error"] != "to must be an enabled currency code" {
+		t.Errorf("failed item error = %v, want the message only", body.Results[1]["error"])
+	}
+}
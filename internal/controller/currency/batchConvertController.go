@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	constants "github.com/ItsDee25/exchange-rate-service/internal/constants/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/controller/apierror"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/gin-gonic/gin"
+)
+
+func (controller *currencyController) BatchConvertCurrencyHandler(c *gin.Context) {
+	var body batchConvertRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		controller.logger.InfoContext(c.Request.Context(), "invalid batch convert request", "error", err)
+		apierror.Abort(c, apierror.InvalidBody("Request body must be a JSON object with an items array"))
+		return
+	}
+	if len(body.Items) == 0 || len(body.Items) > constants.MaxBatchConversionItems {
+		controller.logger.InfoContext(c.Request.Context(), "invalid batch size", "size", len(body.Items))
+		apierror.Abort(c, apierror.InvalidParameter("items", fmt.Sprintf("Batch must contain between 1 and %d items", constants.MaxBatchConversionItems)))
+		return
+	}
+
+	// validate every item, only the valid ones are converted
+	results := make([]batchConvertResult, len(body.Items))
+	requests := make([]domain.ConversionRequest, 0, len(body.Items))
+	indexes := make([]int, 0, len(body.Items))
+	for i, item := range body.Items {
+		req, apiErr := controller.parseBatchConvertItem(c.Request.Context(), item)
+		if apiErr != nil {
+			results[i] = batchConvertResult{
+				Index: i,
+				From:  item.From,
+				To:    item.To,
+				Date:  item.Date,
+				Error: apiErr,
+			}
+			continue
+		}
+		requests = append(requests, req)
+		indexes = append(indexes, i)
+	}
+
+	if len(requests) > 0 {
+		converted := controller.currencyUsecase.BatchConvert(c.Request.Context(), requests)
+		for j, res := range converted {
+			i := indexes[j]
+			result := batchConvertResult{
+				Index:  i,
+				From:   res.Request.From,
+				To:     res.Request.To,
+				Amount: res.Request.Amount.String(),
+				Date:   res.Request.Date,
+			}
+			if res.Err != nil {
+				controller.logger.ErrorContext(c.Request.Context(), "failed to convert batch item", "index", i, "error", res.Err)
+				result.Error = apierror.FromError(res.Err, "Failed to convert currency")
+			} else {
+				rate := res.Conversion.ExchangeRate.Rate
+				derived := res.Conversion.ExchangeRate.Derived()
+				result.Rate = &rate
+				result.Derived = &derived
+				result.ConvertedAmount = res.Conversion.Converted.Amount.String()
+			}
+			results[i] = result
+		}
+	}
+
+	if apierror.IsLegacy(c) {
+		c.JSON(http.StatusOK, newLegacyBatchConvertResponse(results))
+		return
+	}
+	c.JSON(http.StatusOK, batchConvertResponse{Results: results})
+}
+
+// parseBatchConvertItem returns the conversion request for a line item or its validation error
+func (controller *currencyController) parseBatchConvertItem(ctx context.Context, item batchConvertItem) (domain.ConversionRequest, *apierror.Error) {
+	raw := string(item.Amount)
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+	amount, amountErr := parseAmount(raw)
+	if apiErr := firstError(amountErr, controller.validatePair(ctx, item.From, item.To), controller.validateDate("date", item.Date)); apiErr != nil {
+		return domain.ConversionRequest{}, apiErr
+	}
+	return domain.ConversionRequest{
+		From:   item.From,
+		To:     item.To,
+		Date:   item.Date,
+		Amount: amount,
+	}, nil
+}
@@ -1,89 +1,80 @@
 package controller
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
-	"strconv"
 
+	"github.com/ItsDee25/exchange-rate-service/internal/controller/apierror"
 	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
 	"github.com/gin-gonic/gin"
 )
 
 type currencyController struct {
 	currencyUsecase domain.ICurrencyUsecase
+	retentionDays   int
+	logger          *slog.Logger
 }
 
-func NewCurrencyController(u domain.ICurrencyUsecase) *currencyController {
+// NewCurrencyController builds the currency handlers, accepting dates at most retentionDays back
+func NewCurrencyController(u domain.ICurrencyUsecase, retentionDays int) *currencyController {
 	return &currencyController{
 		currencyUsecase: u,
+		retentionDays:   retentionDays,
+		logger:          slog.Default().With("component", "CurrencyController"),
 	}
 }
 
+func (controller *currencyController) WithLogger(logger *slog.Logger) *currencyController {
+	controller.logger = logger.With("component", "CurrencyController")
+	return controller
+}
+
 func (controller *currencyController) ConvertCurrencyHandler(c *gin.Context) {
-	from := c.Query("from")
-	to := c.Query("to")
-	amountStr := c.Query("amount")
-	date := c.Query("date")
+	var req convertRequest
+	_ = c.ShouldBindQuery(&req) // string fields, binding cannot fail
+	ctx, span := tracing.Start(c.Request.Context(), "CurrencyController.ConvertCurrency", tracing.RateAttrs(req.From, req.To, req.Date)...)
+	var err error
+	defer func() { tracing.End(span, err) }()
 
-	amount, err := strconv.ParseFloat(amountStr, 64)
-	if err != nil {
-		log.Println("Error parsing amount:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount"})
-		return
-	}
-	if !isValidCurrency(from) || !isValidCurrency(to) || amount <= 0 {
-		log.Printf("Invalid parameters: from: %s, to: %s, amount %s", from, to, amountStr)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parameters"})
-		return
-	}
-	if date != "" && !isWithin90Days(date) {
-		log.Printf("Invalid date: %s", date)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Date must be within the last 90 days"})
+	amount, amountErr := parseAmount(req.Amount)
+	maxAge, maxAgeErr := parseMaxAge(req.MaxAge)
+	if apiErr := firstError(amountErr, controller.validatePair(ctx, req.From, req.To), controller.validateDate("date", req.Date), maxAgeErr); apiErr != nil {
+		controller.logger.InfoContext(ctx, "invalid parameters", "field", apiErr.Field, "from", req.From, "to", req.To, "amount", req.Amount, "date", req.Date, "max_age", req.MaxAge)
+		apierror.Abort(c, apiErr)
 		return
 	}
 
-	convertedAmount, err := controller.currencyUsecase.GetConvertedCurrency(c.Request.Context(), from, to, date, amount)
+	conversion, err := controller.currencyUsecase.GetConvertedCurrency(ctx, req.From, req.To, req.Date, amount, maxAge)
 	if err != nil {
-		log.Println("Error converting currency:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert currency"})
+		controller.logger.ErrorContext(ctx, "failed to convert currency", "from", req.From, "to", req.To, "date", req.Date, "error", err)
+		apierror.Abort(c, apierror.FromError(err, "Failed to convert currency"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"from":             from,
-		"to":               to,
-		"amount":           amount,
-		"date":             date,
-		"converted_amount": convertedAmount,
-	})
+	c.JSON(http.StatusOK, newConversionResponse(conversion))
 }
 
 func (controller *currencyController) GetExchangeRateHandler(c *gin.Context) {
-	from := c.Query("from")
-	to := c.Query("to")
-	date := c.Query("date")
+	var req exchangeRateRequest
+	_ = c.ShouldBindQuery(&req) // string fields, binding cannot fail
+	ctx, span := tracing.Start(c.Request.Context(), "CurrencyController.GetExchangeRate", tracing.RateAttrs(req.From, req.To, req.Date)...)
+	var err error
+	defer func() { tracing.End(span, err) }()
 
-	if !isValidCurrency(from) || !isValidCurrency(to) {
-		log.Printf("Invalid parameters: from: %s, to: %s", from, to)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parameters"})
-		return
-	}
-	if date != "" && !isWithin90Days(date) {
-		log.Printf("Invalid date: %s", date)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Date must be within the last 90 days"})
+	maxAge, maxAgeErr := parseMaxAge(req.MaxAge)
+	if apiErr := firstError(controller.validatePair(ctx, req.From, req.To), controller.validateDate("date", req.Date), maxAgeErr); apiErr != nil {
+		controller.logger.InfoContext(ctx, "invalid parameters", "field", apiErr.Field, "from", req.From, "to", req.To, "date", req.Date, "max_age", req.MaxAge)
+		apierror.Abort(c, apiErr)
 		return
 	}
 
-	rate, err := controller.currencyUsecase.GetExchangeRate(c.Request.Context(), from, to, date)
+	rate, err := controller.currencyUsecase.GetExchangeRate(ctx, req.From, req.To, req.Date, maxAge)
 	if err != nil {
-		log.Println("Error getting exchange rate:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get exchange rate"})
+		controller.logger.ErrorContext(ctx, "failed to get exchange rate", "from", req.From, "to", req.To, "date", req.Date, "error", err)
+		apierror.Abort(c, apierror.FromError(err, "Failed to get exchange rate"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"from": from,
-		"to":   to,
-		"rate": rate,
-	})
+	c.JSON(http.StatusOK, newExchangeRateResponse(rate))
 }
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/ItsDee25/exchange-rate-service/internal/controller/apierror"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/gin-gonic/gin"
+)
+
+type registryController struct {
+	registryUsecase domain.ICurrencyRegistryUsecase
+	logger          *slog.Logger
+}
+
+func NewRegistryController(u domain.ICurrencyRegistryUsecase) *registryController {
+	return &registryController{
+		registryUsecase: u,
+		logger:          slog.Default().With("component", "RegistryController"),
+	}
+}
+
+func (controller *registryController) WithLogger(logger *slog.Logger) *registryController {
+	controller.logger = logger.With("component", "RegistryController")
+	return controller
+}
+
+func (controller *registryController) ListCurrenciesHandler(c *gin.Context) {
+	currencies := controller.registryUsecase.ListCurrencies(c.Request.Context())
+	response := currenciesResponse{Currencies: make([]currencyResponse, 0, len(currencies))}
+	for _, currency := range currencies {
+		response.Currencies = append(response.Currencies, newCurrencyResponse(currency))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (controller *registryController) UpdateCurrencyHandler(c *gin.Context) {
+	var body currencyUpdateRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		controller.logger.InfoContext(c.Request.Context(), "invalid currency update request", "error", err)
+		apierror.Abort(c, apierror.InvalidBody("Request body must be a JSON object of currency fields"))
+		return
+	}
+
+	currency, err := controller.registryUsecase.UpdateCurrency(c.Request.Context(), domain.CurrencyUpdate{
+		Code:        c.Param("code"),
+		Name:        body.Name,
+		NumericCode: body.NumericCode,
+		MinorUnits:  body.MinorUnits,
+		Enabled:     body.Enabled,
+	})
+	if err != nil {
+		controller.logger.ErrorContext(c.Request.Context(), "failed to update currency", "code", c.Param("code"), "error", err)
+		apierror.Abort(c, apierror.FromError(err, "Failed to update currency"))
+		return
+	}
+
+	c.JSON(http.StatusOK, newCurrencyResponse(currency))
+}
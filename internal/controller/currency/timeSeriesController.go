@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ItsDee25/exchange-rate-service/internal/controller/apierror"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+	"github.com/gin-gonic/gin"
+)
+
+func (controller *currencyController) GetTimeSeriesHandler(c *gin.Context) {
+	var req timeSeriesRequest
+	_ = c.ShouldBindQuery(&req) // string fields, binding cannot fail
+	if req.End == "" {
+		req.End = time.Now().Format(constants.DateLayout)
+	}
+
+	startErr := controller.validateDate("start", req.Start)
+	if req.Start == "" {
+		startErr = apierror.InvalidParameter("start", "start is required")
+	}
+	apiErr := firstError(controller.validatePair(c.Request.Context(), req.From, req.To), startErr, controller.validateDate("end", req.End))
+	if apiErr == nil && req.From == req.To {
+		apiErr = apierror.InvalidParameter("to", "to must differ from from")
+	}
+	if apiErr == nil && req.End < req.Start {
+		apiErr = apierror.InvalidParameter("start", "start must not be after end")
+	}
+	if apiErr != nil {
+		controller.logger.InfoContext(c.Request.Context(), "invalid parameters", "field", apiErr.Field, "from", req.From, "to", req.To, "start", req.Start, "end", req.End)
+		apierror.Abort(c, apiErr)
+		return
+	}
+
+	series, err := controller.currencyUsecase.GetTimeSeries(c.Request.Context(), req.From, req.To, req.Start, req.End)
+	if err != nil {
+		controller.logger.ErrorContext(c.Request.Context(), "failed to get time series", "from", req.From, "to", req.To, "start", req.Start, "end", req.End, "error", err)
+		apierror.Abort(c, apierror.FromError(err, "Failed to get time series"))
+		return
+	}
+
+	rates := make([]timeSeriesPointResponse, 0, len(series.Points))
+	for _, point := range series.Points {
+		rates = append(rates, timeSeriesPointResponse{
+			Date:       point.Date,
+			Rate:       point.Rate,
+			Backfilled: point.Backfilled,
+			Provenance: newProvenanceResponse(point.Provenance),
+		})
+	}
+
+	c.JSON(http.StatusOK, timeSeriesResponse{
+		From:    series.From,
+		To:      series.To,
+		Start:   series.Start,
+		End:     series.End,
+		Rates:   rates,
+		Missing: series.Missing,
+	})
+}
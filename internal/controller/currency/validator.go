@@ -1,22 +1,85 @@
 package controller
 
 import (
+	"context"
+	"fmt"
+	"strconv"
 	"time"
 
-	constants "github.com/ItsDee25/exchange-rate-service/internal/constants/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/controller/apierror"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
 )
 
-func isValidCurrency(code string) bool {
-	_, exists := constants.SupportedCurrencies[code]
-	return exists
+// maxAgeLimit bounds max_age, one year in seconds, so it cannot overflow a duration
+const maxAgeLimit = 365 * 24 * 60 * 60
+
+// isValidCurrency reports whether code is enabled in the currency registry
+func (controller *currencyController) isValidCurrency(ctx context.Context, code string) bool {
+	return controller.currencyUsecase.IsSupportedCurrency(ctx, code)
 }
 
-func isWithin90Days(dateStr string) bool {
-	parsedDate, err := time.Parse("2006-01-02", dateStr)
+// isWithinRetention reports whether dateStr is a past date no older than the retention window
+func (controller *currencyController) isWithinRetention(dateStr string) bool {
+	parsedDate, err := time.Parse(constants.DateLayout, dateStr)
 	if err != nil {
 		return false
 	}
 
-	ninetyDaysAgo := time.Now().AddDate(0, 0, -90)
-	return parsedDate.After(ninetyDaysAgo) && parsedDate.Before(time.Now())
+	oldest := time.Now().AddDate(0, 0, -controller.retentionDays)
+	return parsedDate.After(oldest) && parsedDate.Before(time.Now())
+}
+
+func (controller *currencyController) retentionMessage(subject string) string {
+	return fmt.Sprintf("%s within the last %d days", subject, controller.retentionDays)
+}
+
+// validatePair checks that both currencies are enabled in the registry
+func (controller *currencyController) validatePair(ctx context.Context, from, to string) *apierror.Error {
+	if !controller.isValidCurrency(ctx, from) {
+		return apierror.InvalidParameter("from", "from must be an enabled currency code")
+	}
+	if !controller.isValidCurrency(ctx, to) {
+		return apierror.InvalidParameter("to", "to must be an enabled currency code")
+	}
+	return nil
+}
+
+// validateDate checks an optional date, which defaults to today
+func (controller *currencyController) validateDate(field, date string) *apierror.Error {
+	if date != "" && !controller.isWithinRetention(date) {
+		return apierror.InvalidParameter(field, controller.retentionMessage(field+" must be a date"))
+	}
+	return nil
+}
+
+// parseAmount parses a positive decimal amount
+func parseAmount(raw string) (domain.Decimal, *apierror.Error) {
+	amount, err := domain.NewDecimalFromString(raw)
+	if err != nil || amount.Sign() <= 0 {
+		return domain.Decimal{}, apierror.InvalidParameter("amount", "amount must be a positive decimal")
+	}
+	return amount, nil
+}
+
+// parseMaxAge parses the optional max_age query parameter, a positive number of seconds, 0 when it is absent
+func parseMaxAge(raw string) (time.Duration, *apierror.Error) {
+	if raw == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || seconds <= 0 || seconds > maxAgeLimit {
+		return 0, apierror.InvalidParameter("max_age", "max_age must be a positive number of seconds")
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// firstError returns the first failed validation, in parameter order
+func firstError(errs ...*apierror.Error) *apierror.Error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/ItsDee25/exchange-rate-service/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+type healthController struct {
+	readiness *health.Readiness
+}
+
+func NewHealthController(readiness *health.Readiness) *healthController {
+	return &healthController{readiness: readiness}
+}
+
+// LivenessHandler reports that the process is up and serving, it checks no dependency so a
+// dependency outage never gets the instance restarted
+func (controller *healthController) LivenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// ReadinessHandler reports every check with its latency, answering 503 while a critical check fails
+func (controller *healthController) ReadinessHandler(c *gin.Context) {
+	report := controller.readiness.Check(c.Request.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrRateUnavailable reports that the providers cannot serve a rate at all, e.g. an unsupported pair or a date
+// without data. Unlike timeouts or outages, retrying the same key will not help.
+var ErrRateUnavailable = errors.New("rate unavailable")
+
+// ErrProviderFailed reports that the rate providers answered with an error or an invalid response,
+// the rate may exist.
+var ErrProviderFailed = errors.New("rate provider failed")
+
+// ErrProviderUnavailable reports that the rate providers did not answer in time, retrying later may succeed.
+var ErrProviderUnavailable = errors.New("rate provider unavailable")
+
+// RateUnavailableError is returned for a key that is neither stored nor served by any provider.
+type RateUnavailableError struct {
+	RateKeyRequest
+	Err error
+}
+
+func (e *RateUnavailableError) Error() string {
+	msg := fmt.Sprintf("rate unavailable for %s to %s on %s", e.From, e.To, e.Date)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *RateUnavailableError) Is(target error) bool {
+	return target == ErrRateUnavailable
+}
+
+func (e *RateUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// BatchPartialFailureError reports the keys a batch operation could not read or persist after retries.
+// The rest of the batch succeeded.
+type BatchPartialFailureError struct {
+	Op           string
+	FailedReads  []RateKeyRequest
+	FailedWrites []RateKey
+	Err          error
+}
+
+func (e *BatchPartialFailureError) Error() string {
+	keys := make([]string, 0, len(e.FailedReads)+len(e.FailedWrites))
+	for _, k := range e.FailedReads {
+		keys = append(keys, fmt.Sprintf("%s#%s#%s", k.From, k.To, k.Date))
+	}
+	for _, k := range e.FailedWrites {
+		keys = append(keys, fmt.Sprintf("%s#%s#%s", k.From, k.To, k.Date))
+	}
+	msg := fmt.Sprintf("%s partially failed for %d keys: %s", e.Op, len(keys), strings.Join(keys, ", "))
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *BatchPartialFailureError) Unwrap() error {
+	return e.Err
+}
@@ -6,32 +6,79 @@ import (
 )
 
 type ICurrencyUsecase interface {
-	GetConvertedCurrency(ctx context.Context, from, to, date string, amount float64) (float64, error)
-	GetExchangeRate(ctx context.Context, from, to, date string) (float64, error)
+	// GetConvertedCurrency and GetExchangeRate serve rates no older than maxAge when it is positive
+	GetConvertedCurrency(ctx context.Context, from, to, date string, amount Decimal, maxAge time.Duration) (Conversion, error)
+	GetExchangeRate(ctx context.Context, from, to, date string, maxAge time.Duration) (ExchangeRate, error)
+	BatchConvert(ctx context.Context, req []ConversionRequest) []ConversionResult
+	GetTimeSeries(ctx context.Context, from, to, start, end string) (TimeSeries, error)
+	IsSupportedCurrency(ctx context.Context, code string) bool
+}
+
+type ICurrencyRegistryUsecase interface {
+	ListCurrencies(ctx context.Context) []Currency
+	// UpdateCurrency registers or changes a currency, returning ErrInvalidCurrency for invalid metadata
+	UpdateCurrency(ctx context.Context, update CurrencyUpdate) (Currency, error)
 }
 
 type ICurrencyRepository interface {
-	GetRate(ctx context.Context, from, to, date string) (float64, error)
+	// GetRate skips the cached and stored rate and fetches it again when it is older than a positive maxAge
+	GetRate(ctx context.Context, from, to, date string, maxAge time.Duration) (RateKey, error)
+	GetRates(ctx context.Context, req []RateKeyRequest) (map[RateKeyRequest]RateKey, error)
+	QueryRange(ctx context.Context, from, to, start, end string) ([]RateKey, error)
 }
 
 type IRefresherRepository interface {
 	ICurrencyRepository
 	BatchGetFromDB(ctx context.Context, req []RateKeyRequest) ([]RateKey, error)
-	BatchUpdateDB(ctx context.Context, req []RateKey) error
+	// BatchUpdateDB writes the rates, fence is nil for writes not made on behalf of a lock holder.
+	BatchUpdateDB(ctx context.Context, req []RateKey, fence *FencingToken) error
 	BatchUpdateCache(ctx context.Context, req []RateKey) error
 }
 
 type IRateCache interface {
-	Get(ctx context.Context, key string) (float64, bool)
-	Set(ctx context.Context, key string, value float64)
+	Get(ctx context.Context, key string) (RateKey, bool)
+	Set(ctx context.Context, key string, value RateKey)
 	Delete(ctx context.Context, key string)
+	// SetUnavailable records a negative entry for a key no provider can serve, it expires after a short TTL
+	SetUnavailable(ctx context.Context, key string)
+	IsUnavailable(ctx context.Context, key string) bool
 	ScanAndDeleteExipred(ctx context.Context)
 }
 
+// ICurrencyRegistry is read on every request, implementations serve it from memory
+type ICurrencyRegistry interface {
+	// Lookup returns the registered currency, enabled or not
+	Lookup(ctx context.Context, code string) (Currency, bool)
+	IsEnabled(ctx context.Context, code string) bool
+	List(ctx context.Context) []Currency
+	// EnabledPairs returns every ordered pair of enabled currencies
+	EnabledPairs(ctx context.Context) [][2]string
+}
+
+type ICurrencyRegistryRepository interface {
+	ICurrencyRegistry
+	Put(ctx context.Context, currency Currency) error
+	// Seed registers the currencies that are not registered yet
+	Seed(ctx context.Context, currencies []Currency) error
+	// Reload replaces the in-memory registry with the stored one, picking up changes made by other instances
+	Reload(ctx context.Context) error
+}
+
 type ILocker interface {
-	AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// AcquireLock returns the lease and true if the lock was acquired, false if another owner holds it.
+	AcquireLock(ctx context.Context, key string, ttl time.Duration) (Lease, bool, error)
+	// Renew extends a held lease, returning ErrLockLost if it expired and was taken over.
+	Renew(ctx context.Context, lease Lease, ttl time.Duration) (Lease, error)
+	Release(ctx context.Context, lease Lease) error
 }
 
 type IRateFetcher interface {
 	FetchRate(ctx context.Context, from, to, date string) (float64, error)
 }
+
+type IRateSourceFetcher interface {
+	IRateFetcher
+	FetchRateWithSource(ctx context.Context, from, to, date string) (FetchedRate, error)
+	// Budget is the longest FetchRateWithSource can take, given the provider timeouts
+	Budget() time.Duration
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrLockLost is returned when renewing or releasing a lease that expired and was taken over
+	ErrLockLost = errors.New("lock lost")
+	// ErrStaleFencingToken is returned when a write is fenced with a token older than the lock's current token
+	ErrStaleFencingToken = errors.New("stale fencing token")
+)
+
+// FencingToken identifies a lock holder to guarded writes. Token increases with every acquisition
+// of the lock, so writes from a holder whose lock was taken over can be rejected.
+type FencingToken struct {
+	LockKey string
+	Owner   string
+	Token   int64
+}
+
+// Lease is a held distributed lock.
+type Lease struct {
+	FencingToken
+	ExpiresAt time.Time
+}
@@ -1,12 +1,173 @@
 package domain
 
+import "time"
+
+type RefreshMode string
+
+const (
+	// RefreshModeOnDemand marks rates fetched from the provider on a cache and db miss
+	RefreshModeOnDemand RefreshMode = "on_demand"
+	// RefreshModeScheduled marks rates fetched by the rate refresher job
+	RefreshModeScheduled RefreshMode = "scheduled"
+	// RefreshModeBackfill marks rates fetched by the historical backfill command
+	RefreshModeBackfill RefreshMode = "backfill"
+)
+
+// RateSource is where a served rate was read from
+type RateSource string
+
+const (
+	RateSourceCache    RateSource = "cache"
+	RateSourceDynamo   RateSource = "dynamo"
+	RateSourceProvider RateSource = "provider"
+)
+
 type RateKeyRequest struct {
-	From string 
+	From string
 	To   string
 	Date string
 }
 
+// Provenance records where a stored rate came from.
+type Provenance struct {
+	Provider    string
+	FetchedAt   time.Time
+	RefreshMode RefreshMode
+	// UpdatedAt is when the rate was last written to dynamo, zero for a rate not stored yet
+	UpdatedAt time.Time
+}
+
 type RateKey struct {
 	RateKeyRequest
 	Rate float64
-}
\ No newline at end of file
+	Provenance
+	// Source is where the rate was read from when it was served, it is not stored
+	Source RateSource
+}
+
+// AsOf is when the rate was last stored, or fetched for a rate not stored yet. It is zero for rates stored
+// without either time.
+func (k RateKey) AsOf() time.Time {
+	if !k.UpdatedAt.IsZero() {
+		return k.UpdatedAt
+	}
+	return k.FetchedAt
+}
+
+// OlderThan reports whether the rate is older than maxAge, a rate of unknown age always is
+func (k RateKey) OlderThan(maxAge time.Duration) bool {
+	asOf := k.AsOf()
+	return asOf.IsZero() || time.Since(asOf) > maxAge
+}
+
+// FetchedRate is a rate returned by a third party provider along with the provider that served it.
+type FetchedRate struct {
+	Rate     float64
+	Provider string
+}
+
+type RateMethod string
+
+const (
+	RateMethodIdentity     RateMethod = "identity"
+	RateMethodDirect       RateMethod = "direct"
+	RateMethodInverse      RateMethod = "inverse"
+	RateMethodTriangulated RateMethod = "triangulated"
+)
+
+// RateLeg is a stored rate used to derive an exchange rate. Inverted legs are used in the opposite direction.
+type RateLeg struct {
+	RateKey
+	Inverted bool
+}
+
+func (l RateLeg) EffectiveRate() float64 {
+	if l.Inverted {
+		return 1 / l.Rate
+	}
+	return l.Rate
+}
+
+// ExchangeRate is the rate served for a pair, along with how it was obtained.
+// Provenance is only set for direct rates, derived rates carry it on their legs.
+type ExchangeRate struct {
+	RateKey
+	Method RateMethod
+	Legs   []RateLeg
+}
+
+func (e ExchangeRate) Derived() bool {
+	return e.Method == RateMethodInverse || e.Method == RateMethodTriangulated
+}
+
+// Oldest returns the stored rate bounding the freshness of the exchange rate: the rate itself when it is
+// direct, the leg with the oldest AsOf when it is derived
+func (e ExchangeRate) Oldest() RateKey {
+	if !e.Derived() || len(e.Legs) == 0 {
+		return e.RateKey
+	}
+	oldest := e.Legs[0].RateKey
+	for _, leg := range e.Legs[1:] {
+		if leg.AsOf().Before(oldest.AsOf()) {
+			oldest = leg.RateKey
+		}
+	}
+	return oldest
+}
+
+// Conversion is an amount converted at an exchange rate and rounded to the target currency's minor units.
+type Conversion struct {
+	Amount       Money
+	Converted    Money
+	ExchangeRate ExchangeRate
+}
+
+type ConversionRequest struct {
+	From   string
+	To     string
+	Date   string
+	Amount Decimal
+}
+
+// ConversionResult is the outcome of one item of a batch conversion, Err is set when the item failed.
+type ConversionResult struct {
+	Request    ConversionRequest
+	Conversion Conversion
+	Err        error
+}
+
+// TimeSeriesPoint is the rate of one date in a time series, Backfilled is set when it was missing from storage.
+type TimeSeriesPoint struct {
+	RateKey
+	Backfilled bool
+}
+
+type TimeSeries struct {
+	From   string
+	To     string
+	Start  string
+	End    string
+	Points []TimeSeriesPoint
+	// Missing lists dates that are neither stored nor available from the provider
+	Missing []string
+}
+
+// CoalescingStats counts cache misses: Lookups started a dynamo lookup and provider fetch,
+// Coalesced waited for the result of an identical lookup already in flight.
+type CoalescingStats struct {
+	Lookups   uint64
+	Coalesced uint64
+}
+
+// WriteBehindStats describes the queue persisting rates fetched on cache misses.
+type WriteBehindStats struct {
+	Depth    int
+	Capacity int
+	Enqueued uint64
+	Written  uint64
+	// Dropped rates were not queued because the queue was full or closed
+	Dropped uint64
+	// Failed rates were given up on after retries
+	Failed  uint64
+	Retried uint64
+}
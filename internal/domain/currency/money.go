@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type RoundingMode string
+
+const (
+	// RoundHalfEven rounds ties to the nearest even digit (banker's rounding)
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundHalfUp rounds ties away from zero
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfDown rounds ties towards zero
+	RoundHalfDown RoundingMode = "half_down"
+	// RoundUp rounds away from zero
+	RoundUp RoundingMode = "up"
+	// RoundDown truncates towards zero
+	RoundDown RoundingMode = "down"
+)
+
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	mode := RoundingMode(strings.ToLower(strings.TrimSpace(s)))
+	switch mode {
+	case RoundHalfEven, RoundHalfUp, RoundHalfDown, RoundUp, RoundDown:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown rounding mode %q", s)
+}
+
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d+)?|\.\d+)$`)
+
+// Decimal is an exact decimal number with value unscaled * 10^-scale.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+// NewDecimalFromString parses a plain decimal string such as "100", "-0.5" or "8312.00".
+func NewDecimalFromString(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	var scale int32
+	digits := s
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		scale = int32(len(s) - dot - 1)
+		digits = s[:dot] + s[dot+1:]
+	}
+	unscaled, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal{unscaled: unscaled, scale: scale}, nil
+}
+
+// NewDecimalFromFloat converts f using its shortest decimal representation, so 83.12 stays 83.12.
+func NewDecimalFromFloat(f float64) (Decimal, error) {
+	return NewDecimalFromString(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+func (d Decimal) value() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Mul returns the exact product of d and o.
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{
+		unscaled: new(big.Int).Mul(d.value(), o.value()),
+		scale:    d.scale + o.scale,
+	}
+}
+
+// Round returns d rounded to the given number of decimal places.
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	if places >= d.scale {
+		factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places-d.scale)), nil)
+		return Decimal{unscaled: new(big.Int).Mul(d.value(), factor), scale: places}
+	}
+
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale-places)), nil)
+	quotient, remainder := new(big.Int).QuoRem(d.value(), divisor, new(big.Int))
+	if remainder.Sign() == 0 {
+		return Decimal{unscaled: quotient, scale: places}
+	}
+
+	// compare twice the discarded remainder with the divisor to locate the tie
+	half := new(big.Int).Abs(remainder)
+	half.Mul(half, big.NewInt(2))
+	cmp := half.Cmp(divisor)
+
+	awayFromZero := false
+	switch mode {
+	case RoundUp:
+		awayFromZero = true
+	case RoundDown:
+		awayFromZero = false
+	case RoundHalfUp:
+		awayFromZero = cmp >= 0
+	case RoundHalfDown:
+		awayFromZero = cmp > 0
+	default:
+		awayFromZero = cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1)
+	}
+	if awayFromZero {
+		quotient.Add(quotient, big.NewInt(int64(d.Sign())))
+	}
+	return Decimal{unscaled: quotient, scale: places}
+}
+
+// String formats d with exactly Scale() decimal places.
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.value()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		if d.Sign() == 0 {
+			return "0"
+		}
+		return sign + digits + strings.Repeat("0", int(-d.scale))
+	}
+	if len(digits) <= int(d.scale) {
+		digits = strings.Repeat("0", int(d.scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	parsed, err := NewDecimalFromString(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Money is a decimal amount in an ISO 4217 currency.
+type Money struct {
+	Amount   Decimal
+	Currency string
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Amount.String(), m.Currency)
+}
@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mustDecimal(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := NewDecimalFromString(s)
+	if err != nil {
+		t.Fatalf("NewDecimalFromString(%q): %v", s, err)
+	}
+	return d
+}
+
+func TestNewDecimalFromString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"100", "100"},
+		{"-0.5", "-0.5"},
+		{"+8312.00", "8312.00"},
+		{".5", "0.5"},
+		{"-.05", "-0.05"},
+		{" 42.1 ", "42.1"},
+		{"0.000", "0.000"},
+		{"123456789012345678901234567890.123", "123456789012345678901234567890.123"},
+	}
+	for _, tt := range tests {
+		if got := mustDecimal(t, tt.in).String(); got != tt.want {
+			t.Errorf("NewDecimalFromString(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewDecimalFromStringRejects(t *testing.T) {
+	for _, in := range []string{"", " ", "-", "+", ".", "1.", "1e5", "1.5e-3", "0x10", "1,000", "1.2.3", "--1", "NaN", "Inf", "abc"} {
+		if d, err := NewDecimalFromString(in); err == nil {
+			t.Errorf("NewDecimalFromString(%q) = %s, want an error", in, d)
+		}
+	}
+}
+
+func TestNewDecimalFromFloat(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{83.12, "83.12"},
+		{-0.1, "-0.1"},
+		{1e21, "1000000000000000000000"},
+		{0.000001, "0.000001"},
+		{0, "0"},
+	}
+	for _, tt := range tests {
+		d, err := NewDecimalFromFloat(tt.in)
+		if err != nil {
+			t.Fatalf("NewDecimalFromFloat(%v): %v", tt.in, err)
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("NewDecimalFromFloat(%v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalMul(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"100", "83.12", "8312.00"},
+		{"0.1", "0.2", "0.02"},
+		{"-1.5", "2.25", "-3.375"},
+		{"-2", "-0.5", "1.0"},
+		{"0", "-83.12", "0.00"},
+	}
+	for _, tt := range tests {
+		if got := mustDecimal(t, tt.a).Mul(mustDecimal(t, tt.b)).String(); got != tt.want {
+			t.Errorf("%s * %s = %s, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalRoundTies(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		want   map[RoundingMode]string
+	}{
+		{"2.5", 0, map[RoundingMode]string{RoundHalfEven: "2", RoundHalfUp: "3", RoundHalfDown: "2", RoundUp: "3", RoundDown: "2"}},
+		{"3.5", 0, map[RoundingMode]string{RoundHalfEven: "4", RoundHalfUp: "4", RoundHalfDown: "3", RoundUp: "4", RoundDown: "3"}},
+		{"-2.5", 0, map[RoundingMode]string{RoundHalfEven: "-2", RoundHalfUp: "-3", RoundHalfDown: "-2", RoundUp: "-3", RoundDown: "-2"}},
+		{"-3.5", 0, map[RoundingMode]string{RoundHalfEven: "-4", RoundHalfUp: "-4", RoundHalfDown: "-3", RoundUp: "-4", RoundDown: "-3"}},
+		{"0.015", 2, map[RoundingMode]string{RoundHalfEven: "0.02", RoundHalfUp: "0.02", RoundHalfDown: "0.01", RoundUp: "0.02", RoundDown: "0.01"}},
+		{"0.025", 2, map[RoundingMode]string{RoundHalfEven: "0.02", RoundHalfUp: "0.03", RoundHalfDown: "0.02", RoundUp: "0.03", RoundDown: "0.02"}},
+		{"-0.015", 2, map[RoundingMode]string{RoundHalfEven: "-0.02", RoundHalfUp: "-0.02", RoundHalfDown: "-0.01", RoundUp: "-0.02", RoundDown: "-0.01"}},
+		// not ties: the half modes round to the nearest
+		{"2.51", 0, map[RoundingMode]string{RoundHalfEven: "3", RoundHalfUp: "3", RoundHalfDown: "3", RoundUp: "3", RoundDown: "2"}},
+		{"-2.49", 0, map[RoundingMode]string{RoundHalfEven: "-2", RoundHalfUp: "-2", RoundHalfDown: "-2", RoundUp: "-3", RoundDown: "-2"}},
+		{"0.0001", 2, map[RoundingMode]string{RoundHalfEven: "0.00", RoundHalfUp: "0.00", RoundHalfDown: "0.00", RoundUp: "0.01", RoundDown: "0.00"}},
+	}
+	for _, tt := range tests {
+		for mode, want := range tt.want {
+			if got := mustDecimal(t, tt.in).Round(tt.places, mode).String(); got != want {
+				t.Errorf("%s rounded to %d places %s = %s, want %s", tt.in, tt.places, mode, got, want)
+			}
+		}
+	}
+}
+
+func TestDecimalRoundToMinorUnits(t *testing.T) {
+	// 1234.5675 USD converted at 1.0 keeps the scale of the product until rounded to the currency
+	amount := mustDecimal(t, "1234.5675")
+	tests := []struct {
+		currency   string
+		minorUnits int32
+		want       string
+	}{
+		{"JPY", 0, "1235"},
+		{"USD", 2, "1234.57"},
+		{"KWD", 3, "1234.568"},
+	}
+	for _, tt := range tests {
+		if got := amount.Round(tt.minorUnits, RoundHalfEven).String(); got != tt.want {
+			t.Errorf("%s: Round(%d) = %s, want %s", tt.currency, tt.minorUnits, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalRoundPadsToPlaces(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		want   string
+	}{
+		{"1.5", 3, "1.500"},
+		{"-7", 2, "-7.00"},
+		{"12.30", 2, "12.30"},
+		{"1.20", 1, "1.2"},
+	}
+	for _, tt := range tests {
+		got := mustDecimal(t, tt.in).Round(tt.places, RoundHalfEven)
+		if got.String() != tt.want || got.Scale() != tt.places {
+			t.Errorf("%s rounded to %d places = %s (scale %d), want %s", tt.in, tt.places, got, got.Scale(), tt.want)
+		}
+	}
+}
+
+func TestDecimalString(t *testing.T) {
+	tests := []struct {
+		d    Decimal
+		want string
+	}{
+		{Decimal{}, "0"},
+		{mustDecimal(t, "0.05"), "0.05"},
+		{mustDecimal(t, "-0.05"), "-0.05"},
+		{mustDecimal(t, "-12.345"), "-12.345"},
+		{mustDecimal(t, "5").Round(-1, RoundDown), "0"},
+		{mustDecimal(t, "1234").Round(-2, RoundHalfEven), "1200"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("String() = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestDecimalJSONRoundTrip(t *testing.T) {
+	for _, in := range []string{"8312.00", "-0.015", "0", "123456789012345678901234567890.12"} {
+		data, err := json.Marshal(mustDecimal(t, in))
+		if err != nil {
+			t.Fatalf("Marshal(%s): %v", in, err)
+		}
+		if want := `"` + in + `"`; string(data) != want {
+			t.Errorf("Marshal(%s) = %s, want %s", in, data, want)
+		}
+		var out Decimal
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if out.String() != in {
+			t.Errorf("round trip of %s = %s", in, out)
+		}
+	}
+}
+
+func TestDecimalUnmarshalJSON(t *testing.T) {
+	var d Decimal
+	if err := json.Unmarshal([]byte(`12.5`), &d); err != nil || d.String() != "12.5" {
+		t.Errorf("Unmarshal of a JSON number = %s, %v, want 12.5", d, err)
+	}
+	for _, in := range []string{`"1e5"`, `""`, `"1."`, `null`, `true`} {
+		if err := json.Unmarshal([]byte(in), &d); err == nil {
+			t.Errorf("Unmarshal(%s) = %s, want an error", in, d)
+		}
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for in, want := range map[string]RoundingMode{"half_even": RoundHalfEven, " HALF_UP ": RoundHalfUp, "Down": RoundDown} {
+		if got, err := ParseRoundingMode(in); err != nil || got != want {
+			t.Errorf("ParseRoundingMode(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseRoundingMode("ceiling"); err == nil {
+		t.Error("ParseRoundingMode accepted an unknown mode")
+	}
+}
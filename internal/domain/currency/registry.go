@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidCurrency is returned for a registry change that would store an invalid currency
+var ErrInvalidCurrency = errors.New("invalid currency")
+
+// Currency is an entry of the currency registry, only enabled currencies are served and refreshed
+type Currency struct {
+	// Code is the ISO 4217 alphabetic code, e.g. USD
+	Code string
+	Name string
+	// NumericCode is the ISO 4217 numeric code, e.g. 840
+	NumericCode string
+	// MinorUnits is the number of decimal places converted amounts are rounded to
+	MinorUnits int32
+	Enabled    bool
+	UpdatedAt  time.Time
+}
+
+// CurrencyUpdate is an admin change to the registry. Nil fields keep the stored value, or the
+// ISO 4217 default for a currency not registered yet.
+type CurrencyUpdate struct {
+	Code        string
+	Name        *string
+	NumericCode *string
+	MinorUnits  *int32
+	Enabled     *bool
+}
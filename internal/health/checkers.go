@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+)
+
+// Freshness fails when last reports a time older than maxAge. Until last reports a time, the age is
+// measured from the creation of the checker, giving a job that has not run yet one maxAge to succeed.
+func Freshness(last func() time.Time, maxAge time.Duration) Checker {
+	created := time.Now()
+	return CheckerFunc(func(ctx context.Context) error {
+		at := last()
+		if at.IsZero() {
+			if age := time.Since(created); age > maxAge {
+				return fmt.Errorf("no success in %v", age.Round(time.Second))
+			}
+			return nil
+		}
+		if age := time.Since(at); age > maxAge {
+			return fmt.Errorf("last success %v ago, at %s", age.Round(time.Second), at.UTC().Format(time.RFC3339))
+		}
+		return nil
+	})
+}
+
+// Completed fails until done reports that a startup task, such as the cache warm-up, has finished
+func Completed(done func() bool) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if !done() {
+			return errors.New("in progress")
+		}
+		return nil
+	})
+}
+
+// NotEmpty fails while size reports no entries
+func NotEmpty(size func() int) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if size() == 0 {
+			return errors.New("empty")
+		}
+		return nil
+	})
+}
+
+// Provider fetches today's rate of the first enabled pair. A provider answering that the rate is
+// unavailable is reachable, only errors such as timeouts, outages or bad credentials fail the check.
+func Provider(fetcher domain.IRateSourceFetcher, registry domain.ICurrencyRegistry) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		pairs := registry.EnabledPairs(ctx)
+		if len(pairs) == 0 {
+			return errors.New("no enabled currency pair to probe")
+		}
+		_, err := fetcher.FetchRateWithSource(ctx, pairs[0][0], pairs[0][1], time.Now().Format(constants.DateLayout))
+		if err != nil && !errors.Is(err, domain.ErrRateUnavailable) {
+			return err
+		}
+		return nil
+	})
+}
+
+// cachedChecker reuses the last result of a checker for ttl, so an expensive check such as a
+// rate provider call is not repeated on every probe
+type cachedChecker struct {
+	checker Checker
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// Cached wraps checker, running it at most once per ttl
+func Cached(checker Checker, ttl time.Duration) Checker {
+	return &cachedChecker{checker: checker, ttl: ttl}
+}
+
+func (c *cachedChecker) Check(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.ttl {
+		return c.err
+	}
+	c.err = c.checker.Check(ctx)
+	c.checkedAt = time.Now()
+	return c.err
+}
@@ -0,0 +1,141 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+// Statuses of a check and of the whole report
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusReady    = "ready"
+	StatusDegraded = "degraded"
+	StatusNotReady = "not_ready"
+)
+
+// Checker reports whether a dependency of the service is healthy
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to a Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type registeredCheck struct {
+	name     string
+	checker  Checker
+	critical bool
+}
+
+// Readiness aggregates the checkers deciding whether the instance should receive traffic. A failing
+// critical checker makes the instance not ready, a failing optional one only degrades the report.
+type Readiness struct {
+	checks  []registeredCheck
+	timeout time.Duration
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{timeout: defaultCheckTimeout}
+}
+
+// WithTimeout bounds each check, a check still running when it elapses fails
+func (r *Readiness) WithTimeout(timeout time.Duration) *Readiness {
+	if timeout > 0 {
+		r.timeout = timeout
+	}
+	return r
+}
+
+// Register adds a checker that must pass for the instance to be ready
+func (r *Readiness) Register(name string, checker Checker) *Readiness {
+	r.checks = append(r.checks, registeredCheck{name: name, checker: checker, critical: true})
+	return r
+}
+
+// RegisterOptional adds a checker reported without affecting readiness
+func (r *Readiness) RegisterOptional(name string, checker Checker) *Readiness {
+	r.checks = append(r.checks, registeredCheck{name: name, checker: checker})
+	return r
+}
+
+type CheckResult struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+func (r Report) Ready() bool {
+	return r.Status != StatusNotReady
+}
+
+// Check runs every checker concurrently, each within the check timeout
+func (r *Readiness) Check(ctx context.Context) Report {
+	results := make([]CheckResult, len(r.checks))
+	wg := sync.WaitGroup{}
+	for i, check := range r.checks {
+		wg.Add(1)
+		go func(i int, check registeredCheck) {
+			defer wg.Done()
+			results[i] = r.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusReady, Checks: make(map[string]CheckResult, len(r.checks))}
+	for i, check := range r.checks {
+		result := results[i]
+		report.Checks[check.name] = result
+		if result.Status == StatusOK {
+			continue
+		}
+		if check.critical {
+			report.Status = StatusNotReady
+		} else if report.Status == StatusReady {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run calls the checker, giving up when the timeout elapses even if the checker ignores its context
+func (r *Readiness) run(ctx context.Context, check registeredCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.checker.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{
+		Status:    StatusOK,
+		Critical:  check.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
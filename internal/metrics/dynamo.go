@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pkg "github.com/ItsDee25/exchange-rate-service/pkg/awsclient"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// instrumentedDynamo records the latency and outcome of every dynamo call made through it
+type instrumentedDynamo struct {
+	client pkg.DynamoAPI
+}
+
+// NewInstrumentedDynamo wraps client, recording DynamoDuration for each operation
+func NewInstrumentedDynamo(client pkg.DynamoAPI) pkg.DynamoAPI {
+	return &instrumentedDynamo{client: client}
+}
+
+func observeDynamo(operation string, start time.Time, err error) {
+	outcome := OutcomeSuccess
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		outcome = OutcomeTimeout
+	case err != nil:
+		outcome = OutcomeError
+	}
+	DynamoDuration.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+func (d *instrumentedDynamo) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	start := time.Now()
+	out, err := d.client.GetItem(ctx, params, optFns...)
+	observeDynamo("GetItem", start, err)
+	return out, err
+}
+
+func (d *instrumentedDynamo) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	start := time.Now()
+	out, err := d.client.PutItem(ctx, params, optFns...)
+	observeDynamo("PutItem", start, err)
+	return out, err
+}
+
+func (d *instrumentedDynamo) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	start := time.Now()
+	out, err := d.client.UpdateItem(ctx, params, optFns...)
+	observeDynamo("UpdateItem", start, err)
+	return out, err
+}
+
+func (d *instrumentedDynamo) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	start := time.Now()
+	out, err := d.client.BatchGetItem(ctx, params, optFns...)
+	observeDynamo("BatchGetItem", start, err)
+	return out, err
+}
+
+func (d *instrumentedDynamo) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	start := time.Now()
+	out, err := d.client.BatchWriteItem(ctx, params, optFns...)
+	observeDynamo("BatchWriteItem", start, err)
+	return out, err
+}
+
+func (d *instrumentedDynamo) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	start := time.Now()
+	out, err := d.client.Query(ctx, params, optFns...)
+	observeDynamo("Query", start, err)
+	return out, err
+}
+
+func (d *instrumentedDynamo) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	start := time.Now()
+	out, err := d.client.TransactWriteItems(ctx, params, optFns...)
+	observeDynamo("TransactWriteItems", start, err)
+	return out, err
+}
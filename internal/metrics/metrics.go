@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "exchange_rate"
+
+// Cache lookup results
+const (
+	CacheHit         = "hit"
+	CacheMiss        = "miss"
+	CacheNegativeHit = "negative_hit"
+	// CacheStale is a cached rate skipped because it is older than the max age of the request
+	CacheStale = "stale"
+)
+
+// Outcomes of dynamo and provider calls
+const (
+	OutcomeSuccess     = "success"
+	OutcomeError       = "error"
+	OutcomeUnavailable = "unavailable"
+	OutcomeTimeout     = "timeout"
+)
+
+// Refresher run modes
+const (
+	RefreshModeLocked    = "locked"
+	RefreshModeCacheSync = "cache_sync"
+	RefreshModeLockError = "lock_error"
+)
+
+// Registry holds every metric of the service along with the go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	CacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Rate cache lookups by result: hit, miss, negative_hit (a cached unavailable rate) or stale (a cached rate older than the requested max age).",
+	}, []string{"result"})
+
+	DynamoDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "dynamo_request_duration_seconds",
+		Help:      "Latency of DynamoDB calls by operation and outcome.",
+		Buckets:   []float64{.002, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "outcome"})
+
+	ProviderDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_request_duration_seconds",
+		Help:      "Latency of rate provider calls by provider and outcome.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2, 3, 5, 10},
+	}, []string{"provider", "outcome"})
+
+	ProviderErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_errors_total",
+		Help:      "Failed rate provider calls by provider and outcome: error, unavailable or timeout.",
+	}, []string{"provider", "outcome"})
+
+	RefresherRuns = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "refresher_runs_total",
+		Help:      "Rate refresher runs by mode: locked (fetched and stored the rates), cache_sync (another instance held the lock) or lock_error.",
+	}, []string{"mode"})
+
+	RefresherDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "refresher_run_duration_seconds",
+		Help:      "Duration of rate refresher runs by mode.",
+		Buckets:   []float64{.1, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"mode"})
+
+	LastRefresh = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "refresher_last_success_timestamp_seconds",
+		Help:      "Unix time the rate of the pair was last fetched and stored by the refresher.",
+	}, []string{"from", "to"})
+
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	HTTPInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests being served.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterCacheSize exposes the number of entries of the rate cache
+func RegisterCacheSize(size func() int) {
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_entries",
+		Help:      "Entries held by the rate cache, including negative entries.",
+	}, func() float64 { return float64(size()) })
+}
+
+// RegisterCoalescing exposes how many cache misses started a lookup and how many joined one in flight
+func RegisterCoalescing(stats func() domain.CoalescingStats) {
+	factory.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "coalescing_lookups_total",
+		Help:      "Cache misses that started a dynamo lookup and provider fetch.",
+	}, func() float64 { return float64(stats().Lookups) })
+	factory.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "coalescing_coalesced_total",
+		Help:      "Cache misses that waited on a lookup of the same key already in flight.",
+	}, func() float64 { return float64(stats().Coalesced) })
+}
+
+// RegisterWriteBehind exposes the state of the write-behind queue
+func RegisterWriteBehind(stats func() domain.WriteBehindStats) {
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "write_behind_depth",
+		Help:      "Rates waiting in the write-behind queue.",
+	}, func() float64 { return float64(stats().Depth) })
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "write_behind_capacity",
+		Help:      "Capacity of the write-behind queue.",
+	}, func() float64 { return float64(stats().Capacity) })
+
+	counters := []struct {
+		name, help string
+		value      func(domain.WriteBehindStats) uint64
+	}{
+		{"write_behind_enqueued_total", "Rates queued for writing.", func(s domain.WriteBehindStats) uint64 { return s.Enqueued }},
+		{"write_behind_written_total", "Queued rates written to dynamo.", func(s domain.WriteBehindStats) uint64 { return s.Written }},
+		{"write_behind_dropped_total", "Rates not queued because the queue was full or closed.", func(s domain.WriteBehindStats) uint64 { return s.Dropped }},
+		{"write_behind_failed_total", "Queued rates given up on after retries.", func(s domain.WriteBehindStats) uint64 { return s.Failed }},
+		{"write_behind_retried_total", "Retried rate writes.", func(s domain.WriteBehindStats) uint64 { return s.Retried }},
+	}
+	for _, c := range counters {
+		value := c.value
+		factory.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      c.name,
+			Help:      c.help,
+		}, func() float64 { return float64(value(stats())) })
+	}
+}
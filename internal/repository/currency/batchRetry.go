@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+const (
+	batchGetLimit    = 100
+	batchWriteLimit  = 25
+	batchMaxAttempts = 5
+	batchBaseBackoff = 50 * time.Millisecond
+	batchMaxBackoff  = 2 * time.Second
+)
+
+// backoff sleeps for a full-jitter exponential delay before the given retry attempt (starting at 1).
+// It returns false if the context is done first.
+func backoff(ctx context.Context, attempt int) bool {
+	delay := batchBaseBackoff << (attempt - 1)
+	if delay <= 0 || delay > batchMaxBackoff {
+		delay = batchMaxBackoff
+	}
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(delay) + 1)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
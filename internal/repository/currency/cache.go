@@ -6,37 +6,66 @@ import (
 	"sync"
 	"time"
 
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
 	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
 )
 
+const negativeCacheTTL = 5 * time.Minute
+
 type RateCache struct {
 	cache sync.Map
+	// unavailable holds the expiry time of negative entries
+	unavailable sync.Map
 }
 
 func NewRateCache() *RateCache {
 	return &RateCache{}
 }
 
-func (c *RateCache) Get(ctx context.Context, key string) (float64, bool) {
+func (c *RateCache) Get(ctx context.Context, key string) (domain.RateKey, bool) {
 	val, ok := c.cache.Load(key)
 	if !ok {
-		return 0, false
+		return domain.RateKey{}, false
 	}
-	rate, ok := val.(float64)
+	rate, ok := val.(domain.RateKey)
 	return rate, ok
 }
 
-func (c *RateCache) Set(ctx context.Context, key string, rate float64) {
+func (c *RateCache) Set(ctx context.Context, key string, rate domain.RateKey) {
 	c.cache.Store(key, rate)
+	c.unavailable.Delete(key)
 }
 
 func (c *RateCache) Delete(ctx context.Context, key string) {
 	c.cache.Delete(key)
+	c.unavailable.Delete(key)
+}
+
+func (c *RateCache) SetUnavailable(ctx context.Context, key string) {
+	c.unavailable.Store(key, time.Now().Add(negativeCacheTTL))
+}
+
+func (c *RateCache) IsUnavailable(ctx context.Context, key string) bool {
+	val, ok := c.unavailable.Load(key)
+	if !ok {
+		return false
+	}
+	if expiresAt, ok := val.(time.Time); ok && time.Now().Before(expiresAt) {
+		return true
+	}
+	c.unavailable.Delete(key)
+	return false
 }
 
 func (c *RateCache) ScanAndDeleteExipred(ctx context.Context) {
 	now := time.Now()
 	deleted := 0
+	c.unavailable.Range(func(key, value any) bool {
+		if expiresAt, ok := value.(time.Time); !ok || !now.Before(expiresAt) {
+			c.unavailable.Delete(key)
+		}
+		return true
+	})
 	c.cache.Range(func(key, value any) bool {
 		keyStr, ok := key.(string)
 		if !ok {
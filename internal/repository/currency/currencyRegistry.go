@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	pkg "github.com/ItsDee25/exchange-rate-service/pkg/awsclient"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CurrencyRegistry stores the currency registry in the rates table under pk = CURRENCY, sk = code.
+// Reads are served from an in-memory snapshot, refreshed by Reload and by every Put on this instance.
+type CurrencyRegistry struct {
+	client     pkg.DynamoAPI
+	tableName  string
+	mu         sync.RWMutex
+	currencies map[string]domain.Currency
+	logger     *slog.Logger
+}
+
+func NewCurrencyRegistry(client pkg.DynamoAPI) *CurrencyRegistry {
+	return &CurrencyRegistry{
+		client:     client,
+		tableName:  constants.TableName,
+		currencies: make(map[string]domain.Currency),
+		logger:     slog.Default().With("component", "CurrencyRegistry"),
+	}
+}
+
+func (r *CurrencyRegistry) WithTableName(tableName string) *CurrencyRegistry {
+	r.tableName = tableName
+	return r
+}
+
+func (r *CurrencyRegistry) WithLogger(logger *slog.Logger) *CurrencyRegistry {
+	r.logger = logger.With("component", "CurrencyRegistry")
+	return r
+}
+
+// currencyItem is the dynamo representation of a registered currency
+type currencyItem struct {
+	PK          string `dynamodbav:"pk"`
+	SK          string `dynamodbav:"sk"`
+	Name        string `dynamodbav:"name"`
+	NumericCode string `dynamodbav:"numeric_code"`
+	MinorUnits  int32  `dynamodbav:"minor_units"`
+	Enabled     bool   `dynamodbav:"enabled"`
+	UpdatedAt   int64  `dynamodbav:"updated_at"`
+}
+
+func newCurrencyItem(c domain.Currency) currencyItem {
+	return currencyItem{
+		PK:          constants.CurrencyPartitionKey,
+		SK:          c.Code,
+		Name:        c.Name,
+		NumericCode: c.NumericCode,
+		MinorUnits:  c.MinorUnits,
+		Enabled:     c.Enabled,
+		UpdatedAt:   c.UpdatedAt.Unix(),
+	}
+}
+
+func (i currencyItem) toCurrency() domain.Currency {
+	c := domain.Currency{
+		Code:        i.SK,
+		Name:        i.Name,
+		NumericCode: i.NumericCode,
+		MinorUnits:  i.MinorUnits,
+		Enabled:     i.Enabled,
+	}
+	if i.UpdatedAt > 0 {
+		c.UpdatedAt = time.Unix(i.UpdatedAt, 0).UTC()
+	}
+	return c
+}
+
+func (r *CurrencyRegistry) Lookup(ctx context.Context, code string) (domain.Currency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.currencies[code]
+	return c, ok
+}
+
+func (r *CurrencyRegistry) IsEnabled(ctx context.Context, code string) bool {
+	c, ok := r.Lookup(ctx, code)
+	return ok && c.Enabled
+}
+
+// List returns the registered currencies sorted by code
+func (r *CurrencyRegistry) List(ctx context.Context) []domain.Currency {
+	r.mu.RLock()
+	list := make([]domain.Currency, 0, len(r.currencies))
+	for _, c := range r.currencies {
+		list = append(list, c)
+	}
+	r.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+func (r *CurrencyRegistry) EnabledPairs(ctx context.Context) [][2]string {
+	enabled := make([]string, 0)
+	for _, c := range r.List(ctx) {
+		if c.Enabled {
+			enabled = append(enabled, c.Code)
+		}
+	}
+	pairs := make([][2]string, 0, len(enabled)*(len(enabled)-1))
+	for _, from := range enabled {
+		for _, to := range enabled {
+			if from != to {
+				pairs = append(pairs, [2]string{from, to})
+			}
+		}
+	}
+	return pairs
+}
+
+func (r *CurrencyRegistry) Put(ctx context.Context, currency domain.Currency) error {
+	av, err := attributevalue.MarshalMap(newCurrencyItem(currency))
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("put currency %s failed: %w", currency.Code, err)
+	}
+
+	r.mu.Lock()
+	r.currencies[currency.Code] = currency
+	r.mu.Unlock()
+	return nil
+}
+
+// Seed registers the currencies that are not registered yet, leaving existing entries (possibly changed
+// through the admin api) untouched, then reloads the registry
+func (r *CurrencyRegistry) Seed(ctx context.Context, currencies []domain.Currency) error {
+	for _, currency := range currencies {
+		av, err := attributevalue.MarshalMap(newCurrencyItem(currency))
+		if err != nil {
+			return fmt.Errorf("marshal error: %w", err)
+		}
+		_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:           aws.String(r.tableName),
+			Item:                av,
+			ConditionExpression: aws.String("attribute_not_exists(#pk)"),
+			ExpressionAttributeNames: map[string]string{
+				"#pk": constants.PartitionKey,
+			},
+		})
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("seed currency %s failed: %w", currency.Code, err)
+		}
+		r.logger.InfoContext(ctx, "registered currency", "code", currency.Code)
+	}
+	return r.Reload(ctx)
+}
+
+func (r *CurrencyRegistry) Reload(ctx context.Context) error {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("#pk = :pk"),
+		ExpressionAttributeNames: map[string]string{
+			"#pk": constants.PartitionKey,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: constants.CurrencyPartitionKey},
+		},
+	}
+
+	currencies := make(map[string]domain.Currency)
+	for {
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return fmt.Errorf("query failed: %w", err)
+		}
+		for _, item := range out.Items {
+			var decoded currencyItem
+			if err := attributevalue.UnmarshalMap(item, &decoded); err != nil {
+				r.logger.ErrorContext(ctx, "failed to unmarshal currency", "error", err)
+				continue
+			}
+			currencies[decoded.SK] = decoded.toCurrency()
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+
+	r.mu.Lock()
+	r.currencies = currencies
+	r.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+)
+
+func testCurrency(code, name string, minorUnits int32, enabled bool) domain.Currency {
+	return domain.Currency{
+		Code:       code,
+		Name:       name,
+		MinorUnits: minorUnits,
+		Enabled:    enabled,
+		UpdatedAt:  time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func registryCodes(currencies []domain.Currency) []string {
+	codes := make([]string, len(currencies))
+	for i, c := range currencies {
+		codes[i] = c.Code
+	}
+	return codes
+}
+
+func TestCurrencyRegistrySeed(t *testing.T) {
+	ctx := context.Background()
+	fake := mocks.NewDynamoFake()
+	registry := NewCurrencyRegistry(fake)
+
+	err := registry.Seed(ctx, []domain.Currency{
+		testCurrency("USD", "US Dollar", 2, true),
+		testCurrency("JPY", "Yen", 0, true),
+	})
+	if err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+
+	if got, want := registryCodes(registry.List(ctx)), []string{"JPY", "USD"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %v, want %v sorted by code", got, want)
+	}
+	if jpy, ok := registry.Lookup(ctx, "JPY"); !ok || !reflect.DeepEqual(jpy, testCurrency("JPY", "Yen", 0, true)) {
+		t.Errorf("Lookup(JPY) = %+v, %v, want the seeded currency", jpy, ok)
+	}
+	if len(fake.Items()) != 2 {
+		t.Errorf("%d items stored, want 2", len(fake.Items()))
+	}
+}
+
+func TestCurrencyRegistrySeedDoesNotOverwrite(t *testing.T) {
+	ctx := context.Background()
+	fake := mocks.NewDynamoFake()
+	registry := NewCurrencyRegistry(fake)
+	if err := registry.Seed(ctx, []domain.Currency{testCurrency("USD", "US Dollar", 2, true), testCurrency("EUR", "Euro", 2, true)}); err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+	// disabled through the admin api
+	disabled := testCurrency("EUR", "Euro", 2, false)
+	if err := registry.Put(ctx, disabled); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	// a restart seeds the configured currencies again, one of them new
+	restarted := NewCurrencyRegistry(fake)
+	err := restarted.Seed(ctx, []domain.Currency{
+		testCurrency("USD", "Dollar", 3, true),
+		testCurrency("EUR", "Euro", 2, true),
+		testCurrency("GBP", "Pound Sterling", 2, true),
+	})
+	if err != nil {
+		t.Fatalf("second Seed: %v", err)
+	}
+
+	if eur, _ := restarted.Lookup(ctx, "EUR"); !reflect.DeepEqual(eur, disabled) {
+		t.Errorf("EUR = %+v, want the disabled entry kept", eur)
+	}
+	if usd, _ := restarted.Lookup(ctx, "USD"); usd.Name != "US Dollar" || usd.MinorUnits != 2 {
+		t.Errorf("USD = %+v, want the first seed kept", usd)
+	}
+	if !restarted.IsEnabled(ctx, "GBP") {
+		t.Error("new currency not registered by the second seed")
+	}
+	if got, want := restarted.EnabledPairs(ctx), [][2]string{{"GBP", "USD"}, {"USD", "GBP"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EnabledPairs() = %v, want %v", got, want)
+	}
+}
+
+func TestCurrencyRegistryPut(t *testing.T) {
+	ctx := context.Background()
+	fake := mocks.NewDynamoFake()
+	registry := NewCurrencyRegistry(fake)
+	other := NewCurrencyRegistry(fake)
+	cad := testCurrency("CAD", "Canadian Dollar", 2, true)
+
+	if err := registry.Put(ctx, cad); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if got, ok := registry.Lookup(ctx, "CAD"); !ok || !reflect.DeepEqual(got, cad) {
+		t.Errorf("Lookup(CAD) = %+v, %v, want it served at once by the instance that put it", got, ok)
+	}
+	if _, ok := other.Lookup(ctx, "CAD"); ok {
+		t.Error("another instance sees the change before reloading")
+	}
+	if err := other.Reload(ctx); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got, ok := other.Lookup(ctx, "CAD"); !ok || !reflect.DeepEqual(got, cad) {
+		t.Errorf("Lookup(CAD) after Reload = %+v, %v, want the stored currency", got, ok)
+	}
+}
+
+func TestCurrencyRegistryPutFailureKeepsSnapshot(t *testing.T) {
+	ctx := context.Background()
+	fake := mocks.NewDynamoFake()
+	registry := NewCurrencyRegistry(fake)
+	if err := registry.Put(ctx, testCurrency("USD", "US Dollar", 2, true)); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	fake.FailNext = errors.New("connection refused")
+	if err := registry.Put(ctx, testCurrency("USD", "US Dollar", 2, false)); err == nil {
+		t.Fatal("Put succeeded while dynamo failed")
+	}
+	if !registry.IsEnabled(ctx, "USD") {
+		t.Error("failed Put applied to the in-memory registry")
+	}
+}
+
+func TestCurrencyRegistryReload(t *testing.T) {
+	ctx := context.Background()
+	fake := mocks.NewDynamoFake()
+	fake.QueryPageSize = 2
+	writer := NewCurrencyRegistry(fake)
+	codes := []string{"AUD", "CAD", "CHF", "EUR", "USD"}
+	for _, code := range codes {
+		if err := writer.Put(ctx, testCurrency(code, code, 2, true)); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	// rates share the table and must not be read as currencies
+	if err := newTestRepository(fake).BatchUpdateDB(ctx, testRates([2]string{"USD", "EUR"}, testDates(1)), nil); err != nil {
+		t.Fatalf("BatchUpdateDB: %v", err)
+	}
+
+	registry := NewCurrencyRegistry(fake)
+	if err := registry.Reload(ctx); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := registryCodes(registry.List(ctx)); !reflect.DeepEqual(got, codes) {
+		t.Errorf("List() = %v, want %v across query pages", got, codes)
+	}
+
+	// a failed reload keeps the snapshot
+	fake.FailNext = errors.New("connection refused")
+	if err := registry.Reload(ctx); err == nil {
+		t.Fatal("Reload succeeded while dynamo failed")
+	}
+	if len(registry.List(ctx)) != len(codes) {
+		t.Errorf("failed Reload changed the registry to %v", registryCodes(registry.List(ctx)))
+	}
+}
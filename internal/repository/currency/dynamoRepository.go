@@ -4,38 +4,79 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"time"
 
 	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/metrics"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
+	pkg "github.com/ItsDee25/exchange-rate-service/pkg/awsclient"
 	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
-	ttlDuration     = 90 * 24 * time.Hour
+	ttlDuration = 90 * 24 * time.Hour
+	// dynamoLoadBudget is the time a cache miss may spend reading the rate from dynamo and storing the fetched one,
+	// on top of the provider budget
+	dynamoLoadBudget = 5 * time.Second
+)
+
+var (
+	errRateNotStored = errors.New("rate not found")
+	errRateTooOld    = errors.New("rate older than max age")
 )
 
 type CurrencyDynamoRepository struct {
-	client      *dynamodb.Client
+	client      pkg.DynamoAPI
 	cache       domain.IRateCache
 	tableName   string
-	rateFetcher domain.IRateFetcher
+	rateFetcher domain.IRateSourceFetcher
+	flights     *rateFlightGroup
+	writeBehind *WriteBehindQueue
+	retention   time.Duration
+	logger      *slog.Logger
 }
 
-func NewDynamoRepository(client *dynamodb.Client, rateFetcher domain.IRateFetcher, cache domain.IRateCache) *CurrencyDynamoRepository {
+func NewDynamoRepository(client pkg.DynamoAPI, rateFetcher domain.IRateSourceFetcher, cache domain.IRateCache) *CurrencyDynamoRepository {
 	return &CurrencyDynamoRepository{
 		client:      client,
 		tableName:   constants.TableName,
 		cache:       cache,
 		rateFetcher: rateFetcher,
+		flights:     newRateFlightGroup(rateFetcher.Budget() + dynamoLoadBudget),
+		retention:   ttlDuration,
+		logger:      slog.Default().With("component", "CurrencyRepository"),
 	}
 }
 
+func (r *CurrencyDynamoRepository) WithLogger(logger *slog.Logger) *CurrencyDynamoRepository {
+	r.logger = logger.With("component", "CurrencyRepository")
+	return r
+}
+
+func (r *CurrencyDynamoRepository) WithTableName(tableName string) *CurrencyDynamoRepository {
+	r.tableName = tableName
+	return r
+}
+
+// WithRetention sets how long stored rates live, after which dynamo TTL deletes them
+func (r *CurrencyDynamoRepository) WithRetention(retention time.Duration) *CurrencyDynamoRepository {
+	r.retention = retention
+	return r
+}
+
+// WithWriteBehind persists rates fetched on cache misses through the queue instead of writing them inline.
+func (r *CurrencyDynamoRepository) WithWriteBehind(q *WriteBehindQueue) *CurrencyDynamoRepository {
+	r.writeBehind = q
+	return r
+}
+
 func getPartitionKey(from, to string) string {
 	return fmt.Sprintf("%s#%s", from, to)
 }
@@ -52,15 +93,77 @@ func getCacheKey(from, to, date string) string {
 	return fmt.Sprintf("%s#%s#%s", from, to, date)
 }
 
-func getDynamoItemTTL(date string) (int64, error) {
+func getDynamoItemTTL(date string, retention time.Duration) (int64, error) {
 	rateDate, err := time.Parse(constants.DateLayout, date)
 	if err != nil {
 		return 0, fmt.Errorf("invalid date format: %w", err)
 	}
-	return rateDate.Add(ttlDuration).Unix(), nil
+	return rateDate.Add(retention).Unix(), nil
+}
+
+// rateItem is the dynamo representation of a stored rate
+type rateItem struct {
+	PK          string  `dynamodbav:"pk"`
+	SK          string  `dynamodbav:"sk"`
+	Rate        float64 `dynamodbav:"rate"`
+	Provider    string  `dynamodbav:"provider"`
+	FetchedAt   int64   `dynamodbav:"fetched_at"`
+	UpdatedAt   int64   `dynamodbav:"updated_at"`
+	RefreshMode string  `dynamodbav:"refresh_mode"`
 }
 
-func (r *CurrencyDynamoRepository) GetDataFromDB(ctx context.Context, from, to, date string) (float64, error) {
+func (i rateItem) toRateKey() (domain.RateKey, error) {
+	from, to, err := getFromAndToFromPartitionKey(i.PK)
+	if err != nil {
+		return domain.RateKey{}, err
+	}
+	rate := domain.RateKey{
+		RateKeyRequest: domain.RateKeyRequest{
+			From: from,
+			To:   to,
+			Date: i.SK,
+		},
+		Rate: i.Rate,
+		Provenance: domain.Provenance{
+			Provider:    i.Provider,
+			RefreshMode: domain.RefreshMode(i.RefreshMode),
+		},
+	}
+	if i.FetchedAt > 0 {
+		rate.FetchedAt = time.Unix(i.FetchedAt, 0).UTC()
+	}
+	if i.UpdatedAt > 0 {
+		rate.UpdatedAt = time.Unix(i.UpdatedAt, 0).UTC()
+	}
+	return rate, nil
+}
+
+func getDynamoItem(rate domain.RateKey, retention time.Duration) (map[string]types.AttributeValue, error) {
+	item := map[string]interface{}{
+		constants.PartitionKey: getPartitionKey(rate.From, rate.To),
+		constants.SortKey:      rate.Date,
+		constants.Rate:         rate.Rate,
+		constants.UpdatedAt:    time.Now().Unix(),
+		constants.Provider:     rate.Provider,
+		constants.RefreshMode:  string(rate.RefreshMode),
+	}
+	if !rate.FetchedAt.IsZero() {
+		item[constants.FetchedAt] = rate.FetchedAt.Unix()
+	}
+	ttl, err := getDynamoItemTTL(rate.Date, retention)
+	if err != nil {
+		return nil, err
+	}
+	item[constants.TTL] = ttl
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	return av, nil
+}
+
+func (r *CurrencyDynamoRepository) GetDataFromDB(ctx context.Context, from, to, date string) (domain.RateKey, error) {
 	pk := getPartitionKey(from, to)
 	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(r.tableName),
@@ -70,44 +173,38 @@ func (r *CurrencyDynamoRepository) GetDataFromDB(ctx context.Context, from, to,
 		},
 	})
 	if err != nil {
-		return 0, err
+		return domain.RateKey{}, err
 	}
 	if result.Item == nil {
-		return 0, errors.New("rate not found")
-	}
-
-	var item struct {
-		Rate float64 `dynamodbav:"rate"`
+		return domain.RateKey{}, errRateNotStored
 	}
 
+	var item rateItem
 	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
-		return 0, fmt.Errorf("unmarshal error: %w", err)
+		return domain.RateKey{}, fmt.Errorf("unmarshal error: %w", err)
 	}
 
-	return item.Rate, nil
+	return item.toRateKey()
 }
 
-func (r *CurrencyDynamoRepository) SaveRateInDB(ctx context.Context, from, to, date string, rate float64) error {
-	pk := getPartitionKey(from, to)
-	ttl, err := getDynamoItemTTL(date)
+// Ping reads a key that is never stored, checking that the table is reachable and readable
+func (r *CurrencyDynamoRepository) Ping(ctx context.Context) error {
+	_, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			constants.PartitionKey: &types.AttributeValueMemberS{Value: constants.HealthCheckKey},
+			constants.SortKey:      &types.AttributeValueMemberS{Value: constants.HealthCheckKey},
+		},
+	})
+	return err
+}
 
+func (r *CurrencyDynamoRepository) SaveRateInDB(ctx context.Context, rate domain.RateKey) error {
+	av, err := getDynamoItem(rate, r.retention)
 	if err != nil {
 		return err
 	}
 
-	item := map[string]interface{}{
-		constants.PartitionKey: pk,
-		constants.SortKey:      date,
-		constants.Rate:         rate,
-		constants.UpdatedAt:    time.Now().Unix(),
-		constants.TTL:          ttl,
-	}
-
-	av, err := attributevalue.MarshalMap(item)
-	if err != nil {
-		return fmt.Errorf("marshal error: %w", err)
-	}
-
 	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: aws.String(r.tableName),
 		Item:      av,
@@ -115,40 +212,125 @@ func (r *CurrencyDynamoRepository) SaveRateInDB(ctx context.Context, from, to, d
 	return err
 }
 
-func (r *CurrencyDynamoRepository) GetRate(ctx context.Context, from, to, date string) (float64, error) {
+// GetRate serves the rate from the local cache, then dynamo, then the providers. With a positive maxAge,
+// a cached or stored rate older than maxAge is skipped and the rate is fetched from the providers again.
+func (r *CurrencyDynamoRepository) GetRate(ctx context.Context, from, to, date string, maxAge time.Duration) (rate domain.RateKey, err error) {
+	ctx, span := tracing.Start(ctx, "CurrencyRepository.GetRate", tracing.RateAttrs(from, to, date)...)
+	defer func() { tracing.End(span, err) }()
+	if maxAge > 0 {
+		span.SetAttributes(attribute.String("max_age", maxAge.String()))
+	}
+
 	cacheKey := getCacheKey(from, to, date)
 	// Check local cache first
 	if val, ok := r.cache.Get(ctx, cacheKey); ok {
-		return val, nil
+		if maxAge <= 0 || !val.OlderThan(maxAge) {
+			metrics.CacheLookups.WithLabelValues(metrics.CacheHit).Inc()
+			span.SetAttributes(tracing.AttrCacheHit.Bool(true))
+			val.Source = domain.RateSourceCache
+			return val, nil
+		}
+		metrics.CacheLookups.WithLabelValues(metrics.CacheStale).Inc()
+		span.SetAttributes(tracing.AttrCacheHit.Bool(false), attribute.Bool("cache_stale", true))
+	} else if r.cache.IsUnavailable(ctx, cacheKey) {
+		// a recent miss on both dynamo and the providers is not retried until its negative entry expires
+		metrics.CacheLookups.WithLabelValues(metrics.CacheNegativeHit).Inc()
+		span.SetAttributes(tracing.AttrCacheHit.Bool(false), attribute.Bool("negative_cache_hit", true))
+		return domain.RateKey{}, &domain.RateUnavailableError{RateKeyRequest: domain.RateKeyRequest{From: from, To: to, Date: date}}
+	} else {
+		metrics.CacheLookups.WithLabelValues(metrics.CacheMiss).Inc()
+		span.SetAttributes(tracing.AttrCacheHit.Bool(false))
+	}
+
+	// concurrent misses on the same key and max age share one dynamo lookup and provider fetch
+	flightKey := cacheKey
+	if maxAge > 0 {
+		flightKey += "#" + maxAge.String()
 	}
+	return r.flights.Do(ctx, flightKey, func(ctx context.Context) (domain.RateKey, error) {
+		return r.loadRate(ctx, from, to, date, maxAge)
+	})
+}
+
+// CoalescingStats reports how many cache misses started a lookup and how many waited on one already in flight
+func (r *CurrencyDynamoRepository) CoalescingStats() domain.CoalescingStats {
+	return r.flights.stats()
+}
+
+// loadRate reads the rate from dynamo, falling back to the provider and persisting the fetched rate.
+// A stored rate older than a positive maxAge is fetched again.
+func (r *CurrencyDynamoRepository) loadRate(ctx context.Context, from, to, date string, maxAge time.Duration) (loaded domain.RateKey, err error) {
+	ctx, span := tracing.Start(ctx, "CurrencyRepository.loadRate", tracing.RateAttrs(from, to, date)...)
+	defer func() {
+		if loaded.Provider != "" {
+			span.SetAttributes(tracing.AttrProvider.String(loaded.Provider))
+		}
+		tracing.End(span, err)
+	}()
+
+	cacheKey := getCacheKey(from, to, date)
 
 	// Fetch from DynamoDB
 	rate, err := r.GetDataFromDB(ctx, from, to, date)
+	if err == nil && maxAge > 0 && rate.OlderThan(maxAge) {
+		err = errRateTooOld
+	}
 
 	if err != nil {
-		rate, err = r.rateFetcher.FetchRate(ctx, from, to, date)
+		span.SetAttributes(attribute.String("source", string(domain.RateSourceProvider)))
+		notStored := errors.Is(err, errRateNotStored)
+		if notStored {
+			r.logger.DebugContext(ctx, "rate not stored, fetching from providers", "from", from, "to", to, "date", date)
+		} else if errors.Is(err, errRateTooOld) {
+			r.logger.DebugContext(ctx, "stored rate older than max age, fetching from providers", "from", from, "to", to, "date", date, "as_of", rate.AsOf(), "max_age", maxAge.String())
+		} else {
+			r.logger.WarnContext(ctx, "failed to read rate from db, fetching from providers", "from", from, "to", to, "date", date, "error", err)
+		}
+		fetched, err := r.rateFetcher.FetchRateWithSource(ctx, from, to, date)
 		if err != nil {
-			return 0, err
+			if errors.Is(err, domain.ErrRateUnavailable) {
+				// only a clean miss is cached, a failed dynamo read may have hidden a stored rate
+				if notStored {
+					r.cache.SetUnavailable(ctx, cacheKey)
+				}
+				return domain.RateKey{}, &domain.RateUnavailableError{RateKeyRequest: domain.RateKeyRequest{From: from, To: to, Date: date}, Err: err}
+			}
+			return domain.RateKey{}, err
+		}
+		rate = domain.RateKey{
+			RateKeyRequest: domain.RateKeyRequest{
+				From: from,
+				To:   to,
+				Date: date,
+			},
+			Rate: fetched.Rate,
+			Provenance: domain.Provenance{
+				Provider:    fetched.Provider,
+				FetchedAt:   time.Now().UTC(),
+				RefreshMode: domain.RefreshModeOnDemand,
+			},
 		}
 		r.cache.Set(ctx, cacheKey, rate)
 
-		go func() {
-			err := r.SaveRateInDB(ctx, from, to, date, rate)
-			if err != nil {
-				// TODO: log the error
-			}
-		}()
+		if r.writeBehind != nil {
+			r.writeBehind.Enqueue(rate)
+		} else if err := r.SaveRateInDB(ctx, rate); err != nil {
+			r.logger.ErrorContext(ctx, "failed to save rate", "from", from, "to", to, "date", date, "error", err)
+		}
+		rate.Source = domain.RateSourceProvider
 		return rate, nil
 	}
+	span.SetAttributes(attribute.String("source", string(domain.RateSourceDynamo)))
 	// Update local cache
 	r.cache.Set(ctx, cacheKey, rate)
 
+	rate.Source = domain.RateSourceDynamo
 	return rate, nil
 }
 
-func (r *CurrencyDynamoRepository) SaveRate(ctx context.Context, from, to, date string, rate float64) error {
-	cacheKey := getCacheKey(from, to, date)
-	err := r.SaveRateInDB(ctx, from, to, date, rate)
+func (r *CurrencyDynamoRepository) SaveRate(ctx context.Context, rate domain.RateKey) error {
+	cacheKey := getCacheKey(rate.From, rate.To, rate.Date)
+	err := r.SaveRateInDB(ctx, rate)
 	if err != nil {
 		return fmt.Errorf("error saving rate: %w", err)
 	}
@@ -158,11 +340,93 @@ func (r *CurrencyDynamoRepository) SaveRate(ctx context.Context, from, to, date
 	return nil
 }
 
+// GetRates resolves the distinct keys from the local cache, reading the misses from dynamo in one batch.
+// Keys missing from both are left out of the result and are not fetched from the provider.
+func (r *CurrencyDynamoRepository) GetRates(ctx context.Context, req []domain.RateKeyRequest) (_ map[domain.RateKeyRequest]domain.RateKey, err error) {
+	ctx, span := tracing.Start(ctx, "CurrencyRepository.GetRates", attribute.Int("keys", len(req)))
+	defer func() { tracing.End(span, err) }()
+
+	result := make(map[domain.RateKeyRequest]domain.RateKey, len(req))
+	misses := make([]domain.RateKeyRequest, 0)
+	seen := make(map[domain.RateKeyRequest]struct{}, len(req))
+	for _, k := range req {
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		if val, ok := r.cache.Get(ctx, getCacheKey(k.From, k.To, k.Date)); ok {
+			metrics.CacheLookups.WithLabelValues(metrics.CacheHit).Inc()
+			val.Source = domain.RateSourceCache
+			result[k] = val
+			continue
+		}
+		metrics.CacheLookups.WithLabelValues(metrics.CacheMiss).Inc()
+		misses = append(misses, k)
+	}
+	span.SetAttributes(attribute.Int("cache_misses", len(misses)))
+	if len(misses) == 0 {
+		return result, nil
+	}
+
+	// a partial failure still returns the rates that were read
+	rates, err := r.BatchGetFromDB(ctx, misses)
+	for _, rate := range rates {
+		rate.Source = domain.RateSourceDynamo
+		result[rate.RateKeyRequest] = rate
+	}
+	if cacheErr := r.BatchUpdateCache(ctx, rates); cacheErr != nil {
+		r.logger.ErrorContext(ctx, "failed to update cache in batch", "error", cacheErr)
+	}
+	return result, err
+}
+
+// BatchGetFromDB reads the distinct keys in chunks of 100, retrying UnprocessedKeys with jittered exponential backoff.
+// Keys that still could not be read are reported in a *domain.BatchPartialFailureError alongside the rates that were read,
+// a plain error is returned when no key could be read.
 func (r *CurrencyDynamoRepository) BatchGetFromDB(ctx context.Context, req []domain.RateKeyRequest) ([]domain.RateKey, error) {
 	if len(req) == 0 {
 		return nil, nil
 	}
+	// a BatchGetItem may not contain the same key twice
+	req = dedupeKeys(req)
+
+	result := make([]domain.RateKey, 0, len(req))
+	failed := make([]domain.RateKeyRequest, 0)
+	var lastErr error
+	for start := 0; start < len(req); start += batchGetLimit {
+		end := min(start+batchGetLimit, len(req))
+		rates, unread, err := r.batchGetChunk(ctx, req[start:end])
+		result = append(result, rates...)
+		failed = append(failed, unread...)
+		if err != nil {
+			lastErr = err
+		}
+	}
+
+	if len(failed) == len(req) {
+		return nil, fmt.Errorf("batch get failed for all %d keys: %w", len(req), lastErr)
+	}
+	if len(failed) > 0 {
+		return result, &domain.BatchPartialFailureError{Op: "batch get", FailedReads: failed, Err: lastErr}
+	}
+	return result, nil
+}
+
+// dedupeKeys drops repeated keys, keeping the first occurrence order
+func dedupeKeys(req []domain.RateKeyRequest) []domain.RateKeyRequest {
+	seen := make(map[domain.RateKeyRequest]struct{}, len(req))
+	result := make([]domain.RateKeyRequest, 0, len(req))
+	for _, k := range req {
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		result = append(result, k)
+	}
+	return result
+}
 
+func (r *CurrencyDynamoRepository) batchGetChunk(ctx context.Context, req []domain.RateKeyRequest) ([]domain.RateKey, []domain.RateKeyRequest, error) {
 	keys := make([]map[string]types.AttributeValue, 0, len(req))
 	for _, k := range req {
 		pk := getPartitionKey(k.From, k.To)
@@ -172,101 +436,229 @@ func (r *CurrencyDynamoRepository) BatchGetFromDB(ctx context.Context, req []dom
 		})
 	}
 
-	out, err := r.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
-		RequestItems: map[string]types.KeysAndAttributes{
-			r.tableName: {
-				Keys: keys,
-			},
-		},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("batch get failed: %w", err)
+	result := make([]domain.RateKey, 0, len(req))
+	pending := map[string]types.KeysAndAttributes{
+		r.tableName: {Keys: keys},
 	}
-
-	result := make([]domain.RateKey, 0, len(out.Responses[r.tableName]))
-	for _, item := range out.Responses[r.tableName] {
-		var decoded struct {
-			PK   string  `dynamodbav:"pk"`
-			SK   string  `dynamodbav:"sk"`
-			Rate float64 `dynamodbav:"rate"`
+	var lastErr error
+	for attempt := 0; attempt < batchMaxAttempts && len(pending[r.tableName].Keys) > 0; attempt++ {
+		if attempt > 0 && !backoff(ctx, attempt) {
+			lastErr = ctx.Err()
+			break
 		}
-		if err := attributevalue.UnmarshalMap(item, &decoded); err != nil {
-			log.Printf("unmarshal failed: %v", err)
+		out, err := r.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+			RequestItems: pending,
+		})
+		if err != nil {
+			lastErr = fmt.Errorf("batch get failed: %w", err)
 			continue
 		}
-		from, to, err := getFromAndToFromPartitionKey(decoded.PK)
+		lastErr = nil
+
+		for _, item := range out.Responses[r.tableName] {
+			var decoded rateItem
+			if err := attributevalue.UnmarshalMap(item, &decoded); err != nil {
+				r.logger.ErrorContext(ctx, "failed to unmarshal rate", "error", err)
+				continue
+			}
+			rate, err := decoded.toRateKey()
+			if err != nil {
+				r.logger.ErrorContext(ctx, "failed to decode rate key", "error", err)
+				continue
+			}
+			result = append(result, rate)
+		}
+
+		unprocessed, ok := out.UnprocessedKeys[r.tableName]
+		if !ok {
+			unprocessed = types.KeysAndAttributes{}
+		}
+		pending = map[string]types.KeysAndAttributes{r.tableName: unprocessed}
+	}
+
+	unread := make([]domain.RateKeyRequest, 0, len(pending[r.tableName].Keys))
+	for _, key := range pending[r.tableName].Keys {
+		k, err := rateKeyRequestFromDynamoKey(key)
 		if err != nil {
-			log.Printf("get from/to failed: %v", err)
+			r.logger.ErrorContext(ctx, "failed to decode unprocessed key", "error", err)
 			continue
 		}
-		result = append(result, domain.RateKey{
-			RateKeyRequest: domain.RateKeyRequest{
-				From: from,
-				To:   to,
-				Date: decoded.SK,
-			},
-			Rate: decoded.Rate,
-		})
+		unread = append(unread, k)
+	}
+	if len(unread) > 0 && lastErr == nil {
+		lastErr = fmt.Errorf("unprocessed keys remaining after %d attempts", batchMaxAttempts)
+	}
+	return result, unread, lastErr
+}
+
+func rateKeyRequestFromDynamoKey(key map[string]types.AttributeValue) (domain.RateKeyRequest, error) {
+	var decoded struct {
+		PK string `dynamodbav:"pk"`
+		SK string `dynamodbav:"sk"`
+	}
+	if err := attributevalue.UnmarshalMap(key, &decoded); err != nil {
+		return domain.RateKeyRequest{}, err
+	}
+	from, to, err := getFromAndToFromPartitionKey(decoded.PK)
+	if err != nil {
+		return domain.RateKeyRequest{}, err
+	}
+	return domain.RateKeyRequest{From: from, To: to, Date: decoded.SK}, nil
+}
+
+// QueryRange returns the stored rates of a pair with dates between start and end (inclusive), ordered by date.
+func (r *CurrencyDynamoRepository) QueryRange(ctx context.Context, from, to, start, end string) ([]domain.RateKey, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("#pk = :pk AND #sk BETWEEN :start AND :end"),
+		ExpressionAttributeNames: map[string]string{
+			"#pk": constants.PartitionKey,
+			"#sk": constants.SortKey,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":    &types.AttributeValueMemberS{Value: getPartitionKey(from, to)},
+			":start": &types.AttributeValueMemberS{Value: start},
+			":end":   &types.AttributeValueMemberS{Value: end},
+		},
+	}
+
+	result := make([]domain.RateKey, 0)
+	for {
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %w", err)
+		}
+		for _, item := range out.Items {
+			var decoded rateItem
+			if err := attributevalue.UnmarshalMap(item, &decoded); err != nil {
+				r.logger.ErrorContext(ctx, "failed to unmarshal rate", "error", err)
+				continue
+			}
+			rate, err := decoded.toRateKey()
+			if err != nil {
+				r.logger.ErrorContext(ctx, "failed to decode rate key", "error", err)
+				continue
+			}
+			result = append(result, rate)
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
 	}
 
 	return result, nil
 }
 
-func (r *CurrencyDynamoRepository) BatchUpdateDB(ctx context.Context, rates []domain.RateKey) error {
+// BatchUpdateDB writes the rates in chunks of 25, retrying UnprocessedItems with jittered exponential backoff.
+// With a fencing token the writes are made in transactions guarded by the lock item, and rejected once
+// the token is stale. Rates that were not persisted are reported in a *domain.BatchPartialFailureError,
+// a plain error is returned when none was. Of the rates of the same key only the last one is written.
+func (r *CurrencyDynamoRepository) BatchUpdateDB(ctx context.Context, rates []domain.RateKey, fence *domain.FencingToken) error {
+	return r.batchUpdateDB(ctx, rates, fence, batchMaxAttempts)
+}
+
+// BatchUpdateDBOnce is BatchUpdateDB without a fence making a single BatchWriteItem attempt per chunk,
+// for callers such as the write-behind queue that retry the failed rates themselves.
+func (r *CurrencyDynamoRepository) BatchUpdateDBOnce(ctx context.Context, rates []domain.RateKey) error {
+	return r.batchUpdateDB(ctx, rates, nil, 1)
+}
+
+func (r *CurrencyDynamoRepository) batchUpdateDB(ctx context.Context, rates []domain.RateKey, fence *domain.FencingToken, attempts int) error {
 	if len(rates) == 0 {
 		return nil
 	}
+	// a BatchWriteItem or transaction may not contain the same key twice
+	rates = dedupeRates(rates)
 
+	failed := make([]domain.RateKey, 0)
+	writable := make([]domain.RateKey, 0, len(rates))
 	writeRequests := make([]types.WriteRequest, 0, len(rates))
+	byKey := make(map[string]domain.RateKey, len(rates))
 	for _, rate := range rates {
-		pk := getPartitionKey(rate.From, rate.To)
-		item := map[string]interface{}{
-			constants.PartitionKey: pk,
-			constants.SortKey:      rate.Date,
-			constants.Rate:         rate.Rate,
-			constants.UpdatedAt:    time.Now().Unix(),
-		}
-		ttl, err := getDynamoItemTTL(rate.Date)
-		if err == nil {
-			item[constants.TTL] = ttl
-		}
-
-		av, err := attributevalue.MarshalMap(item)
+		av, err := getDynamoItem(rate, r.retention)
 		if err != nil {
-			log.Printf("marshal error for %v: %v", rate, err)
+			r.logger.ErrorContext(ctx, "failed to marshal rate", "from", rate.From, "to", rate.To, "date", rate.Date, "error", err)
+			failed = append(failed, rate)
 			continue
 		}
+		byKey[getCacheKey(rate.From, rate.To, rate.Date)] = rate
+		writable = append(writable, rate)
 		writeRequests = append(writeRequests, types.WriteRequest{
 			PutRequest: &types.PutRequest{Item: av},
 		})
 	}
 
-	batch := writeRequests
-	for len(batch) > 0 {
-		size := 25
-		if len(batch) < size {
-			size = len(batch)
+	if fence != nil {
+		unwritten, err := r.fencedBatchWrite(ctx, writable, writeRequests, *fence)
+		failed = append(failed, unwritten...)
+		return batchWriteError("fenced batch write", len(rates), failed, err)
+	}
+
+	var lastErr error
+	for start := 0; start < len(writeRequests); start += batchWriteLimit {
+		end := min(start+batchWriteLimit, len(writeRequests))
+		unwritten, err := r.batchWriteChunk(ctx, writeRequests[start:end], attempts)
+		if err != nil {
+			lastErr = err
+		}
+		for _, req := range unwritten {
+			k, err := rateKeyRequestFromDynamoKey(req.PutRequest.Item)
+			if err != nil {
+				r.logger.ErrorContext(ctx, "failed to decode unprocessed item", "error", err)
+				continue
+			}
+			failed = append(failed, byKey[getCacheKey(k.From, k.To, k.Date)])
+		}
+	}
+
+	return batchWriteError("batch write", len(rates), failed, lastErr)
+}
+
+// batchWriteError reports the rates of a batch write that were not persisted, as a plain error when none of total was
+func batchWriteError(op string, total int, failed []domain.RateKey, err error) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	if len(failed) == total {
+		if err == nil {
+			err = errors.New("no rate could be marshalled")
 		}
-		chunk := batch[:size]
-		batch = batch[size:]
+		return fmt.Errorf("%s failed for all %d rates: %w", op, total, err)
+	}
+	return &domain.BatchPartialFailureError{Op: op, FailedWrites: failed, Err: err}
+}
 
-		_, err := r.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+func (r *CurrencyDynamoRepository) batchWriteChunk(ctx context.Context, chunk []types.WriteRequest, attempts int) ([]types.WriteRequest, error) {
+	pending := chunk
+	var lastErr error
+	for attempt := 0; attempt < attempts && len(pending) > 0; attempt++ {
+		if attempt > 0 && !backoff(ctx, attempt) {
+			lastErr = ctx.Err()
+			break
+		}
+		out, err := r.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
 			RequestItems: map[string][]types.WriteRequest{
-				r.tableName: chunk,
+				r.tableName: pending,
 			},
 		})
 		if err != nil {
-			return fmt.Errorf("batch write failed: %w", err)
+			lastErr = fmt.Errorf("batch write failed: %w", err)
+			continue
 		}
+		lastErr = nil
+		pending = out.UnprocessedItems[r.tableName]
 	}
-
-	return nil
+	if len(pending) > 0 && lastErr == nil {
+		lastErr = fmt.Errorf("unprocessed items remaining after %d attempts", attempts)
+	}
+	return pending, lastErr
 }
 
 func (r *CurrencyDynamoRepository) BatchUpdateCache(ctx context.Context, rates []domain.RateKey) error {
 	for _, rate := range rates {
 		cacheKey := getCacheKey(rate.From, rate.To, rate.Date)
-		r.cache.Set(ctx, cacheKey, rate.Rate)
+		r.cache.Set(ctx, cacheKey, rate)
 	}
 	return nil
 }
@@ -0,0 +1,435 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	infra "github.com/ItsDee25/exchange-rate-service/infra/ratefetcher"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// failingDynamo fails every batch call, like an unreachable table, counting them
+type failingDynamo struct {
+	*mocks.DynamoFake
+	batchCalls atomic.Int32
+}
+
+func newFailingDynamo() *failingDynamo {
+	return &failingDynamo{DynamoFake: mocks.NewDynamoFake()}
+}
+
+func (f *failingDynamo) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	f.batchCalls.Add(1)
+	return nil, errors.New("connection refused")
+}
+
+func (f *failingDynamo) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	f.batchCalls.Add(1)
+	return nil, errors.New("connection refused")
+}
+
+func newTestFetcher(fetcher *mocks.MockRateFetcher) domain.IRateSourceFetcher {
+	return infra.NewCompositeFetcher([]infra.NamedFetcher{{Name: "mock", Fetcher: fetcher}})
+}
+
+func newTestRepository(client *mocks.DynamoFake) *CurrencyDynamoRepository {
+	return NewDynamoRepository(client, newTestFetcher(mocks.NewMockRateFetcher()), NewLRURateCache())
+}
+
+// testDates returns n consecutive dates ending yesterday, within the retention window
+func testDates(n int) []string {
+	dates := make([]string, n)
+	for i := range dates {
+		dates[i] = time.Now().AddDate(0, 0, i-n).Format("2006-01-02")
+	}
+	return dates
+}
+
+func testRates(pair [2]string, dates []string) []domain.RateKey {
+	rates := make([]domain.RateKey, 0, len(dates))
+	for i, date := range dates {
+		rates = append(rates, domain.RateKey{
+			RateKeyRequest: domain.RateKeyRequest{From: pair[0], To: pair[1], Date: date},
+			Rate:           80 + float64(i)/100,
+			Provenance:     domain.Provenance{Provider: "mock", RefreshMode: domain.RefreshModeScheduled},
+		})
+	}
+	return rates
+}
+
+func TestBatchGetFromDBTotalFailureIsNotPartial(t *testing.T) {
+	repo := NewDynamoRepository(newFailingDynamo(), newTestFetcher(mocks.NewMockRateFetcher()), NewLRURateCache())
+	req := []domain.RateKeyRequest{{From: "USD", To: "INR", Date: testDates(1)[0]}}
+
+	rates, err := repo.BatchGetFromDB(context.Background(), req)
+	if err == nil {
+		t.Fatal("BatchGetFromDB succeeded against a failing table")
+	}
+	var partial *domain.BatchPartialFailureError
+	if errors.As(err, &partial) {
+		t.Errorf("err = %v, a total failure must not be a partial failure", err)
+	}
+	if len(rates) != 0 {
+		t.Errorf("rates = %v, want none", rates)
+	}
+}
+
+func TestBatchUpdateDBTotalFailureIsNotPartial(t *testing.T) {
+	repo := NewDynamoRepository(newFailingDynamo(), newTestFetcher(mocks.NewMockRateFetcher()), NewLRURateCache())
+
+	err := repo.BatchUpdateDB(context.Background(), testRates([2]string{"USD", "INR"}, testDates(3)), nil)
+	if err == nil {
+		t.Fatal("BatchUpdateDB succeeded against a failing table")
+	}
+	var partial *domain.BatchPartialFailureError
+	if errors.As(err, &partial) {
+		t.Errorf("err = %v, a total failure must not be a partial failure", err)
+	}
+}
+
+func TestBatchUpdateDBDedupesKeys(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake)
+	rates := testRates([2]string{"USD", "INR"}, testDates(2))
+	latest := rates[0]
+	latest.Rate = 99
+	rates = append(rates, latest)
+
+	if err := repo.BatchUpdateDB(context.Background(), rates, nil); err != nil {
+		t.Fatalf("BatchUpdateDB: %v", err)
+	}
+	if calls := fake.Calls("BatchWriteItem"); calls != 1 {
+		t.Errorf("BatchWriteItem calls = %d, want 1", calls)
+	}
+	stored, err := repo.GetDataFromDB(context.Background(), latest.From, latest.To, latest.Date)
+	if err != nil {
+		t.Fatalf("GetDataFromDB: %v", err)
+	}
+	if stored.Rate != 99 {
+		t.Errorf("stored rate = %v, want the last duplicate 99", stored.Rate)
+	}
+}
+
+func TestBatchGetFromDBDedupesKeys(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake)
+	rates := testRates([2]string{"USD", "INR"}, testDates(1))
+	if err := repo.BatchUpdateDB(context.Background(), rates, nil); err != nil {
+		t.Fatalf("BatchUpdateDB: %v", err)
+	}
+
+	req := []domain.RateKeyRequest{rates[0].RateKeyRequest, rates[0].RateKeyRequest}
+	read, err := repo.BatchGetFromDB(context.Background(), req)
+	if err != nil {
+		t.Fatalf("BatchGetFromDB: %v", err)
+	}
+	if len(read) != 1 {
+		t.Errorf("read %d rates, want 1", len(read))
+	}
+}
+
+func TestGetRateFetchesAndStoresMiss(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake)
+	date := testDates(1)[0]
+
+	rate, err := repo.GetRate(context.Background(), "USD", "INR", date, 0)
+	if err != nil {
+		t.Fatalf("GetRate: %v", err)
+	}
+	if rate.Rate != 83.12 || rate.Source != domain.RateSourceProvider || rate.RefreshMode != domain.RefreshModeOnDemand {
+		t.Errorf("rate = %+v, want 83.12 fetched on demand from the provider", rate)
+	}
+	if fake.Calls("GetItem") != 1 || fake.Calls("PutItem") != 1 {
+		t.Errorf("GetItem calls = %d, PutItem calls = %d, want 1 and 1", fake.Calls("GetItem"), fake.Calls("PutItem"))
+	}
+
+	// the next lookup is served by the cache
+	cached, err := repo.GetRate(context.Background(), "USD", "INR", date, 0)
+	if err != nil || cached.Source != domain.RateSourceCache {
+		t.Errorf("second lookup = %+v, %v, want a cache hit", cached, err)
+	}
+
+	// another instance, with an empty cache, reads the stored rate
+	stored, err := newTestRepository(fake).GetRate(context.Background(), "USD", "INR", date, 0)
+	if err != nil {
+		t.Fatalf("GetRate: %v", err)
+	}
+	if stored.Source != domain.RateSourceDynamo || stored.Rate != 83.12 || stored.Provider != "mock" {
+		t.Errorf("stored = %+v, want 83.12 from mock read from dynamo", stored)
+	}
+}
+
+func TestGetRateCachesUnavailableRate(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake)
+	date := testDates(1)[0]
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.GetRate(context.Background(), "USD", "XXX", date, 0); !errors.Is(err, domain.ErrRateUnavailable) {
+			t.Fatalf("err = %v, want ErrRateUnavailable", err)
+		}
+	}
+	// the second miss is answered by the negative cache entry
+	if calls := fake.Calls("GetItem"); calls != 1 {
+		t.Errorf("GetItem calls = %d, want 1", calls)
+	}
+	if calls := fake.Calls("PutItem"); calls != 0 {
+		t.Errorf("PutItem calls = %d, want 0", calls)
+	}
+}
+
+func TestBatchGetFromDBRetriesUnprocessedKeys(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake)
+	dates := testDates(60)
+	rates := append(testRates([2]string{"USD", "INR"}, dates), testRates([2]string{"EUR", "GBP"}, dates)...)
+	if err := repo.BatchUpdateDB(context.Background(), rates, nil); err != nil {
+		t.Fatalf("BatchUpdateDB: %v", err)
+	}
+
+	req := make([]domain.RateKeyRequest, 0, len(rates))
+	for _, rate := range rates {
+		req = append(req, rate.RateKeyRequest)
+	}
+	// both chunks of 100 and 20 keys get half of their keys back unprocessed once
+	fake.UnprocessedBatchCalls = 2
+	read, err := repo.BatchGetFromDB(context.Background(), req)
+	if err != nil {
+		t.Fatalf("BatchGetFromDB: %v", err)
+	}
+	if len(read) != len(req) {
+		t.Errorf("read %d rates, want %d", len(read), len(req))
+	}
+	if calls := fake.Calls("BatchGetItem"); calls != 4 {
+		t.Errorf("BatchGetItem calls = %d, want 2 chunks read in 2 calls each", calls)
+	}
+}
+
+func TestBatchUpdateDBRetriesUnprocessedItems(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake)
+	rates := testRates([2]string{"USD", "INR"}, testDates(60))
+	fake.UnprocessedBatchCalls = 3
+
+	if err := repo.BatchUpdateDB(context.Background(), rates, nil); err != nil {
+		t.Fatalf("BatchUpdateDB: %v", err)
+	}
+	if got := len(fake.Items()); got != len(rates) {
+		t.Errorf("stored %d rates, want %d", got, len(rates))
+	}
+	if calls := fake.Calls("BatchWriteItem"); calls != 6 {
+		t.Errorf("BatchWriteItem calls = %d, want 3 chunks written in 2 calls each", calls)
+	}
+}
+
+func TestBatchUpdateDBReportsPartialFailure(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake)
+	// one chunk, every attempt leaves half of the remaining items unprocessed
+	rates := testRates([2]string{"USD", "INR"}, testDates(batchWriteLimit))
+	fake.UnprocessedBatchCalls = batchMaxAttempts
+
+	err := repo.BatchUpdateDB(context.Background(), rates, nil)
+	var partial *domain.BatchPartialFailureError
+	if !errors.As(err, &partial) {
+		t.Fatalf("err = %v, want a partial failure", err)
+	}
+	if stored := len(fake.Items()); stored+len(partial.FailedWrites) != len(rates) || stored == 0 {
+		t.Errorf("stored %d rates and reported %d failed, want %d in all", stored, len(partial.FailedWrites), len(rates))
+	}
+}
+
+func TestQueryRangeFollowsPagination(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake)
+	dates := testDates(10)
+	rates := append(testRates([2]string{"USD", "INR"}, dates), testRates([2]string{"USD", "EUR"}, dates)...)
+	if err := repo.BatchUpdateDB(context.Background(), rates, nil); err != nil {
+		t.Fatalf("BatchUpdateDB: %v", err)
+	}
+	fake.QueryPageSize = 3
+
+	series, err := repo.QueryRange(context.Background(), "USD", "INR", dates[1], dates[8])
+	if err != nil {
+		t.Fatalf("QueryRange: %v", err)
+	}
+	if len(series) != 8 {
+		t.Fatalf("read %d rates, want 8", len(series))
+	}
+	for i, rate := range series {
+		if rate.Date != dates[i+1] || rate.To != "INR" {
+			t.Errorf("series[%d] = %s %s, want %s INR", i, rate.Date, rate.To, dates[i+1])
+		}
+	}
+	if calls := fake.Calls("Query"); calls != 3 {
+		t.Errorf("Query calls = %d, want 3 pages of 3", calls)
+	}
+}
+
+func TestStoredRatesExpireAfterRetention(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake).WithRetention(48 * time.Hour)
+	rate := testRates([2]string{"USD", "INR"}, testDates(1))[0]
+	if err := repo.SaveRateInDB(context.Background(), rate); err != nil {
+		t.Fatalf("SaveRateInDB: %v", err)
+	}
+	if _, err := repo.GetDataFromDB(context.Background(), rate.From, rate.To, rate.Date); err != nil {
+		t.Fatalf("GetDataFromDB: %v", err)
+	}
+
+	// the ttl is the rate date plus the retention
+	date, _ := time.Parse("2006-01-02", rate.Date)
+	fake.Now = func() time.Time { return date.Add(48*time.Hour + time.Second) }
+	if _, err := repo.GetDataFromDB(context.Background(), rate.From, rate.To, rate.Date); !errors.Is(err, errRateNotStored) {
+		t.Errorf("err = %v, want the expired rate not to be found", err)
+	}
+}
+
+// seedStoredRate stores a USD/INR rate of date with the given updated_at and fetched_at, zero leaving them unset
+func seedStoredRate(t *testing.T, fake *mocks.DynamoFake, date string, rate float64, updatedAt, fetchedAt time.Time) {
+	t.Helper()
+	item := rateItem{PK: getPartitionKey("USD", "INR"), SK: date, Rate: rate, Provider: "mock", RefreshMode: string(domain.RefreshModeScheduled)}
+	if !updatedAt.IsZero() {
+		item.UpdatedAt = updatedAt.Unix()
+	}
+	if !fetchedAt.IsZero() {
+		item.FetchedAt = fetchedAt.Unix()
+	}
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		t.Fatalf("MarshalMap: %v", err)
+	}
+	if err := fake.Seed(av); err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+}
+
+func TestGetRateMaxAge(t *testing.T) {
+	const maxAge = time.Hour
+	now := time.Now().UTC().Truncate(time.Second)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	type provenance struct {
+		updatedAt, fetchedAt time.Time
+	}
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		// cached and stored are the USD/INR rates held by the cache and dynamo, nil for none
+		cached     *provenance
+		stored     *provenance
+		wantSource domain.RateSource
+		wantRate   float64
+		// wantAsOf is the as_of of the served rate, zero for a rate fetched from the provider just now
+		wantAsOf time.Time
+	}{
+		{
+			name:       "cached younger by updated_at",
+			maxAge:     maxAge,
+			cached:     &provenance{updatedAt: ago(10 * time.Minute), fetchedAt: ago(3 * time.Hour)},
+			wantSource: domain.RateSourceCache,
+			wantRate:   80,
+			wantAsOf:   ago(10 * time.Minute),
+		},
+		{
+			name:       "cached without updated_at ages by fetched_at",
+			maxAge:     maxAge,
+			cached:     &provenance{fetchedAt: ago(10 * time.Minute)},
+			wantSource: domain.RateSourceCache,
+			wantRate:   80,
+			wantAsOf:   ago(10 * time.Minute),
+		},
+		{
+			name:       "cached older, stored younger",
+			maxAge:     maxAge,
+			cached:     &provenance{updatedAt: ago(2 * time.Hour)},
+			stored:     &provenance{updatedAt: ago(5 * time.Minute), fetchedAt: ago(3 * time.Hour)},
+			wantSource: domain.RateSourceDynamo,
+			wantRate:   81,
+			wantAsOf:   ago(5 * time.Minute),
+		},
+		{
+			name:       "stored without updated_at ages by fetched_at",
+			maxAge:     maxAge,
+			stored:     &provenance{fetchedAt: ago(5 * time.Minute)},
+			wantSource: domain.RateSourceDynamo,
+			wantRate:   81,
+			wantAsOf:   ago(5 * time.Minute),
+		},
+		{
+			name:       "cached and stored older",
+			maxAge:     maxAge,
+			cached:     &provenance{updatedAt: ago(2 * time.Hour)},
+			stored:     &provenance{updatedAt: ago(2 * time.Hour), fetchedAt: ago(10 * time.Minute)},
+			wantSource: domain.RateSourceProvider,
+			wantRate:   83.12,
+		},
+		{
+			name:       "stored older by fetched_at",
+			maxAge:     maxAge,
+			stored:     &provenance{fetchedAt: ago(2 * time.Hour)},
+			wantSource: domain.RateSourceProvider,
+			wantRate:   83.12,
+		},
+		{
+			name:       "cached of unknown age",
+			maxAge:     maxAge,
+			cached:     &provenance{},
+			wantSource: domain.RateSourceProvider,
+			wantRate:   83.12,
+		},
+		{
+			name:       "no max age serves an old cached rate",
+			cached:     &provenance{updatedAt: ago(2 * time.Hour)},
+			wantSource: domain.RateSourceCache,
+			wantRate:   80,
+			wantAsOf:   ago(2 * time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fake := mocks.NewDynamoFake()
+			cache := NewLRURateCache()
+			repo := NewDynamoRepository(fake, newTestFetcher(mocks.NewMockRateFetcher()), cache)
+			date := testDates(1)[0]
+			if tt.cached != nil {
+				cache.Set(ctx, getCacheKey("USD", "INR", date), domain.RateKey{
+					RateKeyRequest: domain.RateKeyRequest{From: "USD", To: "INR", Date: date},
+					Rate:           80,
+					Provenance:     domain.Provenance{Provider: "mock", UpdatedAt: tt.cached.updatedAt, FetchedAt: tt.cached.fetchedAt},
+				})
+			}
+			if tt.stored != nil {
+				seedStoredRate(t, fake, date, 81, tt.stored.updatedAt, tt.stored.fetchedAt)
+			}
+
+			rate, err := repo.GetRate(ctx, "USD", "INR", date, tt.maxAge)
+			if err != nil {
+				t.Fatalf("GetRate: %v", err)
+			}
+			if rate.Source != tt.wantSource || rate.Rate != tt.wantRate {
+				t.Errorf("rate = %v from %s, want %v from %s", rate.Rate, rate.Source, tt.wantRate, tt.wantSource)
+			}
+			if tt.wantAsOf.IsZero() {
+				if asOf := rate.AsOf(); time.Since(asOf) > time.Minute || !rate.UpdatedAt.IsZero() {
+					t.Errorf("as_of = %v (updated_at %v), want the fetched_at of a rate fetched just now", asOf, rate.UpdatedAt)
+				}
+			} else if !rate.AsOf().Equal(tt.wantAsOf) {
+				t.Errorf("as_of = %v, want %v", rate.AsOf(), tt.wantAsOf)
+			}
+
+			// whatever served it, the rate now satisfies the max age from the cache
+			again, err := repo.GetRate(ctx, "USD", "INR", date, tt.maxAge)
+			if err != nil || again.Source != domain.RateSourceCache || again.Rate != tt.wantRate {
+				t.Errorf("second lookup = %v from %s, %v, want %v from the cache", again.Rate, again.Source, err, tt.wantRate)
+			}
+		})
+	}
+}
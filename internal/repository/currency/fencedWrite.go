@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// a transaction holds at most 100 actions, one of them is the lock condition check
+const fencedWriteLimit = 99
+
+// fencedBatchWrite writes the items in transactions that also check the lock item still carries the
+// fencing token. It stops at the first stale token and returns the rates that were not written.
+func (r *CurrencyDynamoRepository) fencedBatchWrite(ctx context.Context, rates []domain.RateKey, writes []types.WriteRequest, fence domain.FencingToken) ([]domain.RateKey, error) {
+	for start := 0; start < len(writes); start += fencedWriteLimit {
+		end := min(start+fencedWriteLimit, len(writes))
+		if err := r.fencedWriteChunk(ctx, writes[start:end], fence); err != nil {
+			return rates[start:], err
+		}
+	}
+	return nil, nil
+}
+
+func (r *CurrencyDynamoRepository) fencedWriteChunk(ctx context.Context, chunk []types.WriteRequest, fence domain.FencingToken) error {
+	items := make([]types.TransactWriteItem, 0, len(chunk)+1)
+	items = append(items, types.TransactWriteItem{
+		ConditionCheck: &types.ConditionCheck{
+			TableName: aws.String(r.tableName),
+			Key: map[string]types.AttributeValue{
+				constants.PartitionKey: &types.AttributeValueMemberS{Value: fence.LockKey},
+				constants.SortKey:      &types.AttributeValueMemberS{Value: constants.LockSortKey},
+			},
+			ConditionExpression: aws.String("#owner = :owner AND #fence = :fence"),
+			ExpressionAttributeNames: map[string]string{
+				"#owner": constants.Owner,
+				"#fence": constants.Fence,
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":owner": &types.AttributeValueMemberS{Value: fence.Owner},
+				":fence": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", fence.Token)},
+			},
+		},
+	})
+	for _, req := range chunk {
+		items = append(items, types.TransactWriteItem{
+			Put: &types.Put{
+				TableName: aws.String(r.tableName),
+				Item:      req.PutRequest.Item,
+			},
+		})
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < batchMaxAttempts; attempt++ {
+		if attempt > 0 && !backoff(ctx, attempt) {
+			return ctx.Err()
+		}
+		_, err := r.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: items,
+		})
+		if err == nil {
+			return nil
+		}
+
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) && len(canceled.CancellationReasons) > 0 &&
+			aws.ToString(canceled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return fmt.Errorf("lock %s no longer held with token %d: %w", fence.LockKey, fence.Token, domain.ErrStaleFencingToken)
+		}
+		// conflicts and throttling are retried
+		lastErr = fmt.Errorf("fenced batch write failed: %w", err)
+	}
+	return lastErr
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	infra "github.com/ItsDee25/exchange-rate-service/infra/ratefetcher"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+)
+
+const testLockKey = "test_lock"
+
+func acquire(t *testing.T, locker *infra.DynamoLocker) domain.Lease {
+	t.Helper()
+	lease, locked, err := locker.AcquireLock(context.Background(), testLockKey, time.Minute)
+	if err != nil || !locked {
+		t.Fatalf("AcquireLock: locked = %v, err = %v", locked, err)
+	}
+	return lease
+}
+
+func TestFencedBatchUpdateDB(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake)
+	lease := acquire(t, infra.NewDynamoLocker(fake))
+	rates := testRates([2]string{"USD", "INR"}, testDates(3))
+
+	if err := repo.BatchUpdateDB(context.Background(), rates, &lease.FencingToken); err != nil {
+		t.Fatalf("BatchUpdateDB: %v", err)
+	}
+	if calls := fake.Calls("TransactWriteItems"); calls != 1 {
+		t.Errorf("TransactWriteItems calls = %d, want 1", calls)
+	}
+	// the lock item and the rates
+	if got := len(fake.Items()); got != len(rates)+1 {
+		t.Errorf("stored %d items, want %d", got, len(rates)+1)
+	}
+}
+
+func TestFencedBatchUpdateDBRejectsStaleToken(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	repo := newTestRepository(fake)
+	stale := acquire(t, infra.NewDynamoLocker(fake))
+	// the holder stalls past its lease and another instance takes the lock over
+	if err := fake.ExpireItem(testLockKey, constants.LockSortKey); err != nil {
+		t.Fatalf("ExpireItem: %v", err)
+	}
+	current := acquire(t, infra.NewDynamoLocker(fake))
+	rates := testRates([2]string{"USD", "INR"}, testDates(3))
+
+	err := repo.BatchUpdateDB(context.Background(), rates, &stale.FencingToken)
+	if !errors.Is(err, domain.ErrStaleFencingToken) {
+		t.Fatalf("err = %v, want ErrStaleFencingToken", err)
+	}
+	if got := len(fake.Items()); got != 1 {
+		t.Errorf("stored %d items, want only the lock item", got)
+	}
+
+	if err := repo.BatchUpdateDB(context.Background(), rates, &current.FencingToken); err != nil {
+		t.Errorf("BatchUpdateDB with the current token: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+)
+
+const (
+	DefaultCacheMaxEntries         = 10000
+	DefaultCacheMaxNegativeEntries = 1000
+	DefaultCacheCurrentTTL         = 2 * time.Hour
+	DefaultCacheHistoricalTTL      = 24 * time.Hour
+	DefaultCacheNegativeTTL        = 5 * time.Minute
+)
+
+type lruEntry struct {
+	key       string
+	rate      domain.RateKey
+	expiresAt time.Time
+	// unavailable marks a negative entry, it holds no rate
+	unavailable bool
+}
+
+// LRURateCache is a size bounded IRateCache evicting the least recently used entry when full.
+// Every entry carries its own TTL: today's rates may still change so they expire after a short TTL,
+// historical rates are immutable and kept for a long one (never beyond the retention).
+// Negative entries are bounded and evicted apart, so a burst of unknown keys cannot evict rates.
+type LRURateCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// front is most recently used
+	order              *list.List
+	negativeOrder      *list.List
+	maxEntries         int
+	maxNegativeEntries int
+	currentTTL         time.Duration
+	historicalTTL      time.Duration
+	negativeTTL        time.Duration
+	retention          time.Duration
+	now                func() time.Time
+}
+
+func NewLRURateCache() *LRURateCache {
+	return &LRURateCache{
+		entries:            map[string]*list.Element{},
+		order:              list.New(),
+		negativeOrder:      list.New(),
+		maxEntries:         DefaultCacheMaxEntries,
+		maxNegativeEntries: DefaultCacheMaxNegativeEntries,
+		currentTTL:         DefaultCacheCurrentTTL,
+		historicalTTL:      DefaultCacheHistoricalTTL,
+		negativeTTL:        DefaultCacheNegativeTTL,
+		retention:          ttlDuration,
+		now:                time.Now,
+	}
+}
+
+func (c *LRURateCache) WithMaxEntries(maxEntries int) *LRURateCache {
+	if maxEntries > 0 {
+		c.maxEntries = maxEntries
+	}
+	return c
+}
+
+// WithMaxNegativeEntries bounds the negative entries, which are evicted apart from the rates
+func (c *LRURateCache) WithMaxNegativeEntries(maxEntries int) *LRURateCache {
+	if maxEntries > 0 {
+		c.maxNegativeEntries = maxEntries
+	}
+	return c
+}
+
+// WithTTL sets the TTL of today's rates and of historical rates, non positive values keep the defaults
+func (c *LRURateCache) WithTTL(current, historical time.Duration) *LRURateCache {
+	if current > 0 {
+		c.currentTTL = current
+	}
+	if historical > 0 {
+		c.historicalTTL = historical
+	}
+	return c
+}
+
+// WithNegativeTTL sets how long a key no provider can serve is remembered, non positive values keep the default
+func (c *LRURateCache) WithNegativeTTL(ttl time.Duration) *LRURateCache {
+	if ttl > 0 {
+		c.negativeTTL = ttl
+	}
+	return c
+}
+
+// WithRetention sets the age past which rates are not cached, matching the repository retention
+func (c *LRURateCache) WithRetention(retention time.Duration) *LRURateCache {
+	if retention > 0 {
+		c.retention = retention
+	}
+	return c
+}
+
+func (c *LRURateCache) WithClock(now func() time.Time) *LRURateCache {
+	c.now = now
+	return c
+}
+
+func (c *LRURateCache) Get(ctx context.Context, key string) (domain.RateKey, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.lookup(key)
+	if !ok || entry.unavailable {
+		return domain.RateKey{}, false
+	}
+	return entry.rate, true
+}
+
+func (c *LRURateCache) Set(ctx context.Context, key string, rate domain.RateKey) {
+	expiresAt := c.expiry(rate.Date)
+	if !c.now().Before(expiresAt) {
+		return // already past retention
+	}
+	c.store(&lruEntry{key: key, rate: rate, expiresAt: expiresAt})
+}
+
+func (c *LRURateCache) SetUnavailable(ctx context.Context, key string) {
+	c.store(&lruEntry{key: key, expiresAt: c.now().Add(c.negativeTTL), unavailable: true})
+}
+
+func (c *LRURateCache) IsUnavailable(ctx context.Context, key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.lookup(key)
+	return ok && entry.unavailable
+}
+
+// lookup returns the live entry for the key and marks it as recently used, dropping it if expired
+func (c *LRURateCache) lookup(key string) (*lruEntry, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.removeElement(elem)
+		return nil, false
+	}
+	c.listOf(entry).MoveToFront(elem)
+	return entry, true
+}
+
+// store inserts or replaces the entry, evicting the least recently used entries of its kind beyond
+// maxEntries or maxNegativeEntries
+func (c *LRURateCache) store(entry *lruEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[entry.key]; ok {
+		if elem.Value.(*lruEntry).unavailable == entry.unavailable {
+			elem.Value = entry
+			c.listOf(entry).MoveToFront(elem)
+			return
+		}
+		c.removeElement(elem)
+	}
+	order, maxEntries := c.order, c.maxEntries
+	if entry.unavailable {
+		order, maxEntries = c.negativeOrder, c.maxNegativeEntries
+	}
+	c.entries[entry.key] = order.PushFront(entry)
+	for order.Len() > maxEntries {
+		c.removeElement(order.Back())
+	}
+}
+
+func (c *LRURateCache) Delete(ctx context.Context, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// ScanAndDeleteExipred drops every expired entry, entries are otherwise only dropped when read or evicted
+func (c *LRURateCache) ScanAndDeleteExipred(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for _, order := range []*list.List{c.order, c.negativeOrder} {
+		for elem := order.Back(); elem != nil; {
+			prev := elem.Prev()
+			if !now.Before(elem.Value.(*lruEntry).expiresAt) {
+				c.removeElement(elem)
+			}
+			elem = prev
+		}
+	}
+}
+
+// Len returns the number of cached rates, negative entries excluded
+func (c *LRURateCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRURateCache) removeElement(elem *list.Element) {
+	entry := elem.Value.(*lruEntry)
+	c.listOf(entry).Remove(elem)
+	delete(c.entries, entry.key)
+}
+
+func (c *LRURateCache) listOf(entry *lruEntry) *list.List {
+	if entry.unavailable {
+		return c.negativeOrder
+	}
+	return c.order
+}
+
+// expiry returns when a rate for the given date leaves the cache. Today is taken in UTC, as the date is parsed.
+func (c *LRURateCache) expiry(date string) time.Time {
+	now := c.now().UTC()
+	rateDate, err := time.Parse(constants.DateLayout, date)
+	if err != nil || date >= now.Format(constants.DateLayout) {
+		return now.Add(c.currentTTL)
+	}
+	expiresAt := now.Add(c.historicalTTL)
+	if retention := rateDate.Add(c.retention); retention.Before(expiresAt) {
+		return retention
+	}
+	return expiresAt
+}
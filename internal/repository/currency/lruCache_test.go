@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+// testClock is a settable clock for the cache
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestCache(clock *testClock) *LRURateCache {
+	return NewLRURateCache().
+		WithClock(clock.Now).
+		WithTTL(time.Hour, 24*time.Hour).
+		WithNegativeTTL(5 * time.Minute).
+		WithRetention(72 * time.Hour)
+}
+
+func cacheRate(date string) (string, domain.RateKey) {
+	rate := domain.RateKey{RateKeyRequest: domain.RateKeyRequest{From: "USD", To: "EUR", Date: date}, Rate: 0.92}
+	return fmt.Sprintf("%s#%s#%s", rate.From, rate.To, rate.Date), rate
+}
+
+func TestLRURateCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)}
+	cache := newTestCache(clock).WithMaxEntries(2)
+	keyA, rateA := cacheRate("2024-06-08")
+	keyB, rateB := cacheRate("2024-06-09")
+	keyC, rateC := cacheRate("2024-06-10")
+
+	cache.Set(ctx, keyA, rateA)
+	cache.Set(ctx, keyB, rateB)
+	cache.Get(ctx, keyA) // A is now more recently used than B
+	cache.Set(ctx, keyC, rateC)
+
+	if _, ok := cache.Get(ctx, keyB); ok {
+		t.Error("least recently used entry not evicted")
+	}
+	for _, key := range []string{keyA, keyC} {
+		if _, ok := cache.Get(ctx, key); !ok {
+			t.Errorf("%s evicted, want it kept", key)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+}
+
+func TestLRURateCacheSetReplacesWithoutEvicting(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestCache(&testClock{now: time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)}).WithMaxEntries(2)
+	keyA, rateA := cacheRate("2024-06-09")
+	keyB, rateB := cacheRate("2024-06-10")
+	cache.Set(ctx, keyA, rateA)
+	cache.Set(ctx, keyB, rateB)
+
+	rateA.Rate = 0.93
+	cache.Set(ctx, keyA, rateA)
+
+	if got, ok := cache.Get(ctx, keyA); !ok || got.Rate != 0.93 {
+		t.Errorf("Get(%s) = %+v, %v, want the replaced rate", keyA, got, ok)
+	}
+	if _, ok := cache.Get(ctx, keyB); !ok || cache.Len() != 2 {
+		t.Errorf("replacing an entry evicted another, Len() = %d", cache.Len())
+	}
+}
+
+func TestLRURateCacheTTL(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		date string
+		// ttl is how long the rate stays cached, zero if it is not cached at all
+		ttl time.Duration
+	}{
+		{"today expires after the current TTL", "2024-06-10", time.Hour},
+		{"historical expires after the historical TTL", "2024-06-09", 24 * time.Hour},
+		{"historical never outlives the retention", "2024-06-08", 12 * time.Hour},
+		{"past the retention is not cached", "2024-06-07", 0},
+		{"an invalid date is treated as today", "not-a-date", time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := &testClock{now: now}
+			cache := newTestCache(clock)
+			key, rate := cacheRate(tt.date)
+			cache.Set(ctx, key, rate)
+			if tt.ttl == 0 {
+				if cache.Len() != 0 {
+					t.Errorf("rate of %s cached, want it dropped", tt.date)
+				}
+				return
+			}
+
+			clock.now = now.Add(tt.ttl - time.Second)
+			if _, ok := cache.Get(ctx, key); !ok {
+				t.Fatalf("rate of %s expired before %v", tt.date, tt.ttl)
+			}
+			clock.now = now.Add(tt.ttl)
+			if _, ok := cache.Get(ctx, key); ok {
+				t.Errorf("rate of %s still cached after %v", tt.date, tt.ttl)
+			}
+			if cache.Len() != 0 {
+				t.Errorf("expired entry kept after a read, Len() = %d", cache.Len())
+			}
+		})
+	}
+}
+
+func TestLRURateCacheTodayIsUTC(t *testing.T) {
+	ctx := context.Background()
+	// late on June 10 in UTC, already June 11 in the local zone
+	local := time.FixedZone("UTC+5", 5*60*60)
+	now := time.Date(2024, 6, 10, 23, 30, 0, 0, time.UTC).In(local)
+	clock := &testClock{now: now}
+	cache := newTestCache(clock)
+	key, rate := cacheRate("2024-06-10")
+
+	cache.Set(ctx, key, rate)
+
+	clock.now = now.Add(time.Hour)
+	if _, ok := cache.Get(ctx, key); ok {
+		t.Error("today's rate in UTC cached with the historical TTL")
+	}
+}
+
+func TestLRURateCacheNegativeEntries(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)}
+	cache := newTestCache(clock)
+	key, rate := cacheRate("2024-06-09")
+
+	cache.SetUnavailable(ctx, key)
+	if !cache.IsUnavailable(ctx, key) {
+		t.Fatal("negative entry not found")
+	}
+	if _, ok := cache.Get(ctx, key); ok {
+		t.Error("Get returned a rate for a negative entry")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d, negative entries must not count as rates", cache.Len())
+	}
+
+	clock.now = clock.now.Add(5 * time.Minute)
+	if cache.IsUnavailable(ctx, key) {
+		t.Error("negative entry still found after its TTL")
+	}
+
+	cache.SetUnavailable(ctx, key)
+	cache.Set(ctx, key, rate)
+	if cache.IsUnavailable(ctx, key) {
+		t.Error("negative entry kept once the rate was cached")
+	}
+	if _, ok := cache.Get(ctx, key); !ok || cache.Len() != 1 {
+		t.Errorf("rate replacing a negative entry not cached, Len() = %d", cache.Len())
+	}
+}
+
+func TestLRURateCacheNegativeEntriesCannotEvictRates(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestCache(&testClock{now: time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)}).
+		WithMaxEntries(2).
+		WithMaxNegativeEntries(3)
+	keyA, rateA := cacheRate("2024-06-09")
+	keyB, rateB := cacheRate("2024-06-10")
+	cache.Set(ctx, keyA, rateA)
+	cache.Set(ctx, keyB, rateB)
+
+	unknown := make([]string, 10)
+	for i := range unknown {
+		unknown[i] = fmt.Sprintf("USD#X%02d#2024-06-10", i)
+		cache.SetUnavailable(ctx, unknown[i])
+	}
+
+	for _, key := range []string{keyA, keyB} {
+		if _, ok := cache.Get(ctx, key); !ok {
+			t.Errorf("rate %s evicted by negative entries", key)
+		}
+	}
+	for i, key := range unknown {
+		if want := i >= len(unknown)-3; cache.IsUnavailable(ctx, key) != want {
+			t.Errorf("IsUnavailable(%s) = %v, want only the 3 most recent negative entries kept", key, !want)
+		}
+	}
+}
+
+func TestLRURateCacheScanAndDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	clock := &testClock{now: now}
+	cache := newTestCache(clock)
+	keyToday, rateToday := cacheRate("2024-06-10")
+	keyOld, rateOld := cacheRate("2024-06-09")
+	cache.Set(ctx, keyToday, rateToday)
+	cache.Set(ctx, keyOld, rateOld)
+	cache.SetUnavailable(ctx, "USD#XXX#2024-06-10")
+
+	clock.now = now.Add(2 * time.Hour)
+	cache.ScanAndDeleteExipred(ctx)
+
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d after the scan, want only the historical rate left", cache.Len())
+	}
+	clock.now = now
+	if _, ok := cache.Get(ctx, keyToday); ok {
+		t.Error("expired rate not deleted by the scan")
+	}
+	if cache.IsUnavailable(ctx, "USD#XXX#2024-06-10") {
+		t.Error("expired negative entry not deleted by the scan")
+	}
+	if _, ok := cache.Get(ctx, keyOld); !ok {
+		t.Error("live rate deleted by the scan")
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+type rateFlightCall struct {
+	done chan struct{}
+	rate domain.RateKey
+	err  error
+}
+
+// rateFlightGroup coalesces concurrent lookups of the same key: the first caller starts the lookup and
+// every caller arriving while it is in flight waits for the shared result instead of starting its own.
+type rateFlightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*rateFlightCall
+	// timeout bounds each shared lookup, so a hung call does not block its waiters forever
+	timeout time.Duration
+
+	started   atomic.Uint64
+	coalesced atomic.Uint64
+}
+
+func newRateFlightGroup(timeout time.Duration) *rateFlightGroup {
+	return &rateFlightGroup{calls: map[string]*rateFlightCall{}, timeout: timeout}
+}
+
+// Do returns the result of fn for the key, running it once for all concurrent callers.
+// fn runs detached from the callers' cancellation so one caller giving up does not fail the others, under
+// the group timeout instead. Each caller still stops waiting when its own ctx is done.
+func (g *rateFlightGroup) Do(ctx context.Context, key string, fn func(ctx context.Context) (domain.RateKey, error)) (domain.RateKey, error) {
+	g.mu.Lock()
+	call, inFlight := g.calls[key]
+	if inFlight {
+		g.coalesced.Add(1)
+	} else {
+		call = &rateFlightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		g.started.Add(1)
+		go g.run(context.WithoutCancel(ctx), key, call, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.rate, call.err
+	case <-ctx.Done():
+		return domain.RateKey{}, ctx.Err()
+	}
+}
+
+func (g *rateFlightGroup) run(ctx context.Context, key string, call *rateFlightCall, fn func(ctx context.Context) (domain.RateKey, error)) {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	defer func() {
+		if p := recover(); p != nil {
+			call.err = fmt.Errorf("rate lookup for %s panicked: %v", key, p)
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.rate, call.err = fn(ctx)
+}
+
+func (g *rateFlightGroup) stats() domain.CoalescingStats {
+	return domain.CoalescingStats{
+		Lookups:   g.started.Load(),
+		Coalesced: g.coalesced.Load(),
+	}
+}
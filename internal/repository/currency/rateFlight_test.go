@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+func TestRateFlightGroupCoalesces(t *testing.T) {
+	g := newRateFlightGroup(time.Second)
+	release := make(chan struct{})
+	var runs atomic.Int32
+	fn := func(ctx context.Context) (domain.RateKey, error) {
+		runs.Add(1)
+		<-release
+		return domain.RateKey{Rate: 83.12}, nil
+	}
+
+	const callers = 10
+	wg := sync.WaitGroup{}
+	results := make([]domain.RateKey, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = g.Do(context.Background(), "USD#INR#2024-06-01", fn)
+		}(i)
+	}
+	// let every caller join the flight before it completes
+	for g.stats().Lookups+g.stats().Coalesced < callers {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if runs.Load() != 1 {
+		t.Errorf("lookup ran %d times, want 1", runs.Load())
+	}
+	for i, res := range results {
+		if res.Rate != 83.12 {
+			t.Errorf("caller %d got %v, want 83.12", i, res.Rate)
+		}
+	}
+	if stats := g.stats(); stats.Lookups != 1 || stats.Coalesced != callers-1 {
+		t.Errorf("stats = %+v, want 1 lookup and %d coalesced", stats, callers-1)
+	}
+}
+
+func TestRateFlightGroupTimesOutHungLookup(t *testing.T) {
+	g := newRateFlightGroup(50 * time.Millisecond)
+	hung := func(ctx context.Context) (domain.RateKey, error) {
+		<-ctx.Done()
+		return domain.RateKey{}, ctx.Err()
+	}
+
+	start := time.Now()
+	_, err := g.Do(context.Background(), "USD#INR#2024-06-01", hung)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %s for a lookup bounded by 50ms", elapsed)
+	}
+}
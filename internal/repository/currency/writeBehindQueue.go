@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+const (
+	DefaultWriteBehindCapacity      = 1000
+	DefaultWriteBehindWorkers       = 2
+	DefaultWriteBehindBatchSize     = batchWriteLimit
+	DefaultWriteBehindFlushInterval = 500 * time.Millisecond
+	DefaultWriteBehindMaxAttempts   = 5
+)
+
+var ErrWriteBehindClosed = errors.New("write-behind queue is closed")
+
+// WriteBehindQueue persists rates off the request path. Rates are buffered in a bounded queue and written
+// by a pool of workers in batches through BatchUpdateDB, retrying the rates that failed with backoff.
+// Enqueue never blocks: when the queue is full the rate is dropped, it is fetched again on a later miss.
+type WriteBehindQueue struct {
+	write         func(ctx context.Context, rates []domain.RateKey) error
+	queue         chan domain.RateKey
+	workers       int
+	batchSize     int
+	flushInterval time.Duration
+	maxAttempts   int
+	logger        *slog.Logger
+
+	mu      sync.RWMutex
+	started bool
+	closed  bool
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	enqueued atomic.Uint64
+	written  atomic.Uint64
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+	retried  atomic.Uint64
+}
+
+// NewWriteBehindQueue builds a queue writing batches with write, usually CurrencyDynamoRepository.BatchUpdateDBOnce.
+// The queue is the only retry layer: write should make a single attempt and report the rates it did not
+// persist in a *domain.BatchPartialFailureError.
+func NewWriteBehindQueue(write func(ctx context.Context, rates []domain.RateKey) error) *WriteBehindQueue {
+	return &WriteBehindQueue{
+		write:         write,
+		queue:         make(chan domain.RateKey, DefaultWriteBehindCapacity),
+		workers:       DefaultWriteBehindWorkers,
+		batchSize:     DefaultWriteBehindBatchSize,
+		flushInterval: DefaultWriteBehindFlushInterval,
+		maxAttempts:   DefaultWriteBehindMaxAttempts,
+		logger:        slog.Default().With("component", "WriteBehind"),
+	}
+}
+
+func (q *WriteBehindQueue) WithLogger(logger *slog.Logger) *WriteBehindQueue {
+	q.logger = logger.With("component", "WriteBehind")
+	return q
+}
+
+// WithCapacity bounds the number of queued rates, it must be called before Start
+func (q *WriteBehindQueue) WithCapacity(capacity int) *WriteBehindQueue {
+	if capacity > 0 {
+		q.queue = make(chan domain.RateKey, capacity)
+	}
+	return q
+}
+
+func (q *WriteBehindQueue) WithWorkers(workers int) *WriteBehindQueue {
+	if workers > 0 {
+		q.workers = workers
+	}
+	return q
+}
+
+// WithBatching sets the largest batch a worker writes and how long it waits to fill one
+func (q *WriteBehindQueue) WithBatching(size int, flushInterval time.Duration) *WriteBehindQueue {
+	if size > 0 {
+		q.batchSize = size
+	}
+	if flushInterval > 0 {
+		q.flushInterval = flushInterval
+	}
+	return q
+}
+
+func (q *WriteBehindQueue) WithMaxAttempts(attempts int) *WriteBehindQueue {
+	if attempts > 0 {
+		q.maxAttempts = attempts
+	}
+	return q
+}
+
+func (q *WriteBehindQueue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started {
+		return
+	}
+	q.started = true
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	q.logger.Info("starting write-behind workers", "workers", q.workers)
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+}
+
+// Enqueue queues the rate for writing, returning false if it was dropped because the queue is full or closed
+func (q *WriteBehindQueue) Enqueue(rate domain.RateKey) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		q.dropped.Add(1)
+		return false
+	}
+	select {
+	case q.queue <- rate:
+		q.enqueued.Add(1)
+		return true
+	default:
+		q.dropped.Add(1)
+		q.logger.Warn("write-behind queue full, dropping rate", "from", rate.From, "to", rate.To, "date", rate.Date)
+		return false
+	}
+}
+
+// Drain stops accepting rates and waits for the queued ones to be written. If ctx is done first the
+// in-flight writes are cancelled and the rates still queued are counted as failed.
+func (q *WriteBehindQueue) Drain(ctx context.Context) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrWriteBehindClosed
+	}
+	q.closed = true
+	close(q.queue)
+	started := q.started
+	q.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		q.cancel()
+		q.logger.Info("write-behind queue drained", "written", q.written.Load(), "failed", q.failed.Load())
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (q *WriteBehindQueue) Stats() domain.WriteBehindStats {
+	return domain.WriteBehindStats{
+		Depth:    len(q.queue),
+		Capacity: cap(q.queue),
+		Enqueued: q.enqueued.Load(),
+		Written:  q.written.Load(),
+		Dropped:  q.dropped.Load(),
+		Failed:   q.failed.Load(),
+		Retried:  q.retried.Load(),
+	}
+}
+
+// worker collects rates into batches, writing a batch when it is full, when the flush interval
+// elapses or when the queue is closed
+func (q *WriteBehindQueue) worker() {
+	defer q.wg.Done()
+	ticker := time.NewTicker(q.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.RateKey, 0, q.batchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			q.writeBatch(batch)
+			batch = make([]domain.RateKey, 0, q.batchSize)
+		}
+	}
+	for {
+		select {
+		case rate, ok := <-q.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, rate)
+			if len(batch) >= q.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// writeBatch writes the batch, retrying only the rates reported as failed
+func (q *WriteBehindQueue) writeBatch(batch []domain.RateKey) {
+	pending := dedupeRates(batch)
+	for attempt := 1; ; attempt++ {
+		if q.ctx.Err() != nil {
+			q.failed.Add(uint64(len(pending)))
+			q.logger.Warn("write-behind cancelled, rates not written", "count", len(pending))
+			return
+		}
+		err := q.write(q.ctx, pending)
+		if err == nil {
+			q.written.Add(uint64(len(pending)))
+			return
+		}
+
+		var partial *domain.BatchPartialFailureError
+		if errors.As(err, &partial) {
+			q.written.Add(uint64(len(pending) - len(partial.FailedWrites)))
+			pending = partial.FailedWrites
+		}
+		if attempt >= q.maxAttempts || !backoff(q.ctx, attempt) {
+			q.failed.Add(uint64(len(pending)))
+			q.logger.Error("write-behind giving up on rates", "count", len(pending), "attempts", attempt, "error", err)
+			return
+		}
+		q.retried.Add(uint64(len(pending)))
+	}
+}
+
+// dedupeRates keeps the latest rate per key, a batch write may not contain the same key twice
+func dedupeRates(rates []domain.RateKey) []domain.RateKey {
+	index := make(map[domain.RateKeyRequest]int, len(rates))
+	result := make([]domain.RateKey, 0, len(rates))
+	for _, rate := range rates {
+		if i, ok := index[rate.RateKeyRequest]; ok {
+			result[i] = rate
+			continue
+		}
+		index[rate.RateKeyRequest] = len(result)
+		result = append(result, rate)
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+)
+
+func TestWriteBehindQueueWritesQueuedRates(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	// the first write only persists half of the batch, the queue retries the rest
+	fake.UnprocessedBatchCalls = 1
+	repo := newTestRepository(fake)
+	q := NewWriteBehindQueue(repo.BatchUpdateDBOnce).WithBatching(10, 10*time.Millisecond)
+	q.Start()
+
+	rates := testRates([2]string{"USD", "INR"}, testDates(4))
+	for _, rate := range rates {
+		if !q.Enqueue(rate) {
+			t.Fatalf("rate %v dropped", rate.RateKeyRequest)
+		}
+	}
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+
+	if got := len(fake.Items()); got != len(rates) {
+		t.Errorf("stored %d rates, want %d", got, len(rates))
+	}
+	stats := q.Stats()
+	if stats.Written != uint64(len(rates)) || stats.Failed != 0 || stats.Retried == 0 {
+		t.Errorf("stats = %+v, want %d written, 0 failed, some retried", stats, len(rates))
+	}
+}
+
+func TestWriteBehindQueueHasASingleRetryLayer(t *testing.T) {
+	client := newFailingDynamo()
+	repo := NewDynamoRepository(client, newTestFetcher(mocks.NewMockRateFetcher()), NewLRURateCache())
+	q := NewWriteBehindQueue(repo.BatchUpdateDBOnce).WithBatching(10, 10*time.Millisecond).WithMaxAttempts(3)
+	q.Start()
+
+	rates := testRates([2]string{"USD", "INR"}, testDates(2))
+	for _, rate := range rates {
+		q.Enqueue(rate)
+	}
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+
+	// one BatchWriteItem per queue attempt, not one retry loop nested in another
+	if calls := client.batchCalls.Load(); calls != 3 {
+		t.Errorf("BatchWriteItem calls = %d, want 3", calls)
+	}
+	if stats := q.Stats(); stats.Failed != uint64(len(rates)) {
+		t.Errorf("failed = %d, want %d", stats.Failed, len(rates))
+	}
+}
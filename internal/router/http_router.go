@@ -1,28 +1,97 @@
 package router
 
 import (
+	"crypto/subtle"
+	"log/slog"
 	"net/http"
 
 	"github.com/ItsDee25/exchange-rate-service/cmd/server/bootstrap/builders"
+	"github.com/ItsDee25/exchange-rate-service/internal/config"
+	"github.com/ItsDee25/exchange-rate-service/internal/controller/apierror"
 	controller "github.com/ItsDee25/exchange-rate-service/internal/controller/currency"
+	healthcontroller "github.com/ItsDee25/exchange-rate-service/internal/controller/health"
+	"github.com/ItsDee25/exchange-rate-service/internal/health"
+	"github.com/ItsDee25/exchange-rate-service/internal/metrics"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(r *gin.Engine, usecases *builders.Usecases) {
+// apiVersion prefixes the current api, the unversioned routes are deprecated aliases of it
+const apiVersion = "/v1"
 
-	// health check endpoint
+// currencyHandlers are served under both /v1 and the deprecated unversioned routes, registryHandlers under /v1 only
+type currencyHandlers interface {
+	ConvertCurrencyHandler(c *gin.Context)
+	GetExchangeRateHandler(c *gin.Context)
+	BatchConvertCurrencyHandler(c *gin.Context)
+	GetTimeSeriesHandler(c *gin.Context)
+}
+
+type registryHandlers interface {
+	ListCurrenciesHandler(c *gin.Context)
+	UpdateCurrencyHandler(c *gin.Context)
+}
+
+func RegisterRoutes(r *gin.Engine, usecases *builders.Usecases, readiness *health.Readiness, cfg config.Config, logger *slog.Logger) {
+	r.Use(requestID(), tracing.GinMiddleware(), accessLog(logger), recovery(logger), metrics.GinMiddleware())
+	r.NoRoute(func(c *gin.Context) {
+		apierror.Abort(c, apierror.New(http.StatusNotFound, apierror.CodeNotFound, "Route not found"))
+	})
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// health check endpoint, kept for existing probes, it reports liveness only
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"status": "ok",
 		})
 	})
+	healthController := healthcontroller.NewHealthController(readiness)
+	r.GET(livenessRoute, healthController.LivenessHandler)
+	r.GET(readinessRoute, healthController.ReadinessHandler)
 
-	registerCurrencyRoutes(r, usecases)
+	currencyController := controller.NewCurrencyController(usecases.CurrencyUsecase, cfg.Currency.RetentionDays).
+		WithLogger(logger)
+	registryController := controller.NewRegistryController(usecases.CurrencyRegistryUsecase).
+		WithLogger(logger)
+	if cfg.Server.AdminToken == "" {
+		logger.Info("admin api disabled, set ADMIN_API_TOKEN to enable it")
+	}
+
+	v1 := r.Group(apiVersion)
+	registerCurrencyRoutes(v1, currencyController)
+	registerAdminRoutes(v1, registryController, cfg.Server.AdminToken)
+
+	// the currency routes served before versioning keep their bodies and error shape, with deprecation
+	// headers, the admin api is only served under /v1
+	legacy := r.Group("", deprecated(apiVersion))
+	registerCurrencyRoutes(legacy, currencyController)
 }
 
-func registerCurrencyRoutes(r *gin.Engine, usecases *builders.Usecases) {
+func registerCurrencyRoutes(r *gin.RouterGroup, controller currencyHandlers) {
 	group := r.Group("/currency")
-	controller := controller.NewCurrencyController(usecases.CurrencyUsecase)
 	group.GET("/convert", controller.ConvertCurrencyHandler)
 	group.GET("/exchangeRate", controller.GetExchangeRateHandler)
+	group.POST("/convert/batch", controller.BatchConvertCurrencyHandler)
+	group.GET("/timeseries", controller.GetTimeSeriesHandler)
+}
+
+// registerAdminRoutes exposes the currency registry management, only when an admin token is configured
+func registerAdminRoutes(r *gin.RouterGroup, controller registryHandlers, adminToken string) {
+	if adminToken == "" {
+		return
+	}
+	group := r.Group("/admin", requireBearerToken(adminToken))
+	group.GET("/currencies", controller.ListCurrenciesHandler)
+	group.PUT("/currencies/:code", controller.UpdateCurrencyHandler)
+}
+
+func requireBearerToken(token string) gin.HandlerFunc {
+	expected := []byte("Bearer " + token)
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), expected) != 1 {
+			apierror.Abort(c, apierror.New(http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized"))
+			return
+		}
+		c.Next()
+	}
 }
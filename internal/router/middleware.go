@@ -0,0 +1,115 @@
+package router
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ItsDee25/exchange-rate-service/internal/controller/apierror"
+	"github.com/ItsDee25/exchange-rate-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	livenessRoute  = "/livez"
+	readinessRoute = "/readyz"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// maxRequestIDLength bounds the incoming ids propagated into the logs
+	maxRequestIDLength = 128
+)
+
+// requestID propagates the X-Request-ID of the request, or assigns a new one, through the request context
+// so every record logged with it carries the id. The id is echoed in the response header.
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// isValidRequestID accepts ids of printable characters safe to log, rejecting anything that could forge log lines
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString([]byte(time.Now().UTC().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(b)
+}
+
+// probeRoutes are polled every few seconds by the orchestrator, their successful requests are logged at debug level
+var probeRoutes = map[string]bool{
+	"/health":      true,
+	livenessRoute:  true,
+	readinessRoute: true,
+}
+
+// accessLog logs every request once served, at warn level for 5xx responses
+func accessLog(l *slog.Logger) gin.HandlerFunc {
+	l = l.With("component", "HTTP")
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		level := slog.LevelInfo
+		switch {
+		case c.Writer.Status() >= http.StatusInternalServerError:
+			level = slog.LevelWarn
+		case probeRoutes[c.FullPath()]:
+			level = slog.LevelDebug
+		}
+		l.Log(c.Request.Context(), level, "request served",
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
+
+// recovery turns a panicking handler into a 500, logging the panic with the request id
+func recovery(l *slog.Logger) gin.HandlerFunc {
+	l = l.With("component", "HTTP")
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		l.ErrorContext(c.Request.Context(), "handler panicked", "panic", err, "path", c.Request.URL.Path)
+		apierror.Abort(c, apierror.Internal("Internal server error"))
+	})
+}
+
+// deprecated marks the routes kept from before versioning, pointing clients to the same route under prefix.
+// Their errors keep the shape served before versioning.
+func deprecated(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apierror.UseLegacyShape(c)
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+prefix+c.FullPath()+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+
+	pkg "github.com/ItsDee25/exchange-rate-service/pkg/awsclient"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedDynamo records a client span for every dynamo call made through it
+type tracedDynamo struct {
+	client pkg.DynamoAPI
+}
+
+// NewTracedDynamo wraps client, starting a DynamoDB.<operation> span for each call
+func NewTracedDynamo(client pkg.DynamoAPI) pkg.DynamoAPI {
+	return &tracedDynamo{client: client}
+}
+
+func startDynamo(ctx context.Context, operation string, table *string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemDynamoDB,
+		semconv.DBOperationName(operation),
+	}
+	if table != nil {
+		attrs = append(attrs, semconv.AWSDynamoDBTableNames(aws.ToString(table)))
+	}
+	return tracer().Start(ctx, "DynamoDB."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func (d *tracedDynamo) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	ctx, span := startDynamo(ctx, "GetItem", params.TableName)
+	out, err := d.client.GetItem(ctx, params, optFns...)
+	if err == nil {
+		span.SetAttributes(attribute.Bool("found", out.Item != nil))
+	}
+	End(span, err)
+	return out, err
+}
+
+func (d *tracedDynamo) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	ctx, span := startDynamo(ctx, "PutItem", params.TableName)
+	out, err := d.client.PutItem(ctx, params, optFns...)
+	End(span, err)
+	return out, err
+}
+
+func (d *tracedDynamo) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	ctx, span := startDynamo(ctx, "UpdateItem", params.TableName)
+	out, err := d.client.UpdateItem(ctx, params, optFns...)
+	End(span, err)
+	return out, err
+}
+
+func (d *tracedDynamo) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	ctx, span := startDynamo(ctx, "BatchGetItem", nil)
+	keys := 0
+	for _, req := range params.RequestItems {
+		keys += len(req.Keys)
+	}
+	span.SetAttributes(attribute.Int("keys", keys))
+	out, err := d.client.BatchGetItem(ctx, params, optFns...)
+	End(span, err)
+	return out, err
+}
+
+func (d *tracedDynamo) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	ctx, span := startDynamo(ctx, "BatchWriteItem", nil)
+	items := 0
+	for _, req := range params.RequestItems {
+		items += len(req)
+	}
+	span.SetAttributes(attribute.Int("items", items))
+	out, err := d.client.BatchWriteItem(ctx, params, optFns...)
+	End(span, err)
+	return out, err
+}
+
+func (d *tracedDynamo) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	ctx, span := startDynamo(ctx, "Query", params.TableName)
+	out, err := d.client.Query(ctx, params, optFns...)
+	if err == nil {
+		span.SetAttributes(attribute.Int("items", len(out.Items)))
+	}
+	End(span, err)
+	return out, err
+}
+
+func (d *tracedDynamo) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	ctx, span := startDynamo(ctx, "TransactWriteItems", nil)
+	out, err := d.client.TransactWriteItems(ctx, params, optFns...)
+	End(span, err)
+	return out, err
+}
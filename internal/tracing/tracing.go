@@ -0,0 +1,133 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/ItsDee25/exchange-rate-service"
+
+// Span exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+// Span attributes shared across the layers
+const (
+	AttrPair     = attribute.Key("pair")
+	AttrDate     = attribute.Key("date")
+	AttrCacheHit = attribute.Key("cache_hit")
+	AttrProvider = attribute.Key("provider")
+)
+
+type Options struct {
+	// Exporter is none, stdout (written to stderr, away from the logs), file or otlp
+	Exporter string
+	// File is the file spans are appended to by the file exporter
+	File string
+	// Endpoint is the OTLP/HTTP collector url, OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318 when empty
+	Endpoint string
+	// SampleRatio is the fraction of new traces recorded, traces started upstream follow the caller's decision
+	SampleRatio float64
+	ServiceName string
+}
+
+// Setup installs the global tracer provider and W3C trace context propagation. The returned function
+// flushes the buffered spans and stops the exporter. With the none exporter spans are not recorded.
+func Setup(ctx context.Context, opts Options) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if opts.Exporter == ExporterNone || opts.Exporter == "" {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	exporter, closeOutput, err := newExporter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(opts.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		return errors.Join(provider.Shutdown(ctx), closeOutput())
+	}, nil
+}
+
+func newExporter(ctx context.Context, opts Options) (sdktrace.SpanExporter, func() error, error) {
+	noop := func() error { return nil }
+	switch opts.Exporter {
+	case ExporterStdout:
+		// stdout carries the JSON logs, spans interleaved with them would break log collection
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+		return exporter, noop, err
+	case ExporterFile:
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return exporter, f.Close, nil
+	case ExporterOTLP:
+		clientOpts := make([]otlptracehttp.Option, 0, 1)
+		if opts.Endpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		}
+		exporter, err := otlptracehttp.New(ctx, clientOpts...)
+		return exporter, noop, err
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span from the global tracer provider
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RateAttrs are the attributes identifying a rate lookup
+func RateAttrs(from, to, date string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		AttrPair.String(from + "/" + to),
+		AttrDate.String(date),
+	}
+}
+
+// End marks the span failed when err is set, then ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
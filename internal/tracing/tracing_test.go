@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useTracerProvider installs provider as the global tracer provider for the test
+func useTracerProvider(t *testing.T, provider *sdktrace.TracerProvider) {
+	t.Helper()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+}
+
+func TestStartAndEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	useTracerProvider(t, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	_, ok := Start(context.Background(), "ok", RateAttrs("USD", "INR", "2024-06-01")...)
+	End(ok, nil)
+	_, failed := Start(context.Background(), "failed")
+	End(failed, errors.New("provider failed"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended %d spans, want 2", len(spans))
+	}
+	if status := spans[0].Status().Code; status != codes.Unset {
+		t.Errorf("status = %v, want unset", status)
+	}
+	attrs := map[string]string{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs[string(AttrPair)] != "USD/INR" || attrs[string(AttrDate)] != "2024-06-01" {
+		t.Errorf("attributes = %v, want pair USD/INR and date 2024-06-01", attrs)
+	}
+	if status := spans[1].Status(); status.Code != codes.Error || status.Description != "provider failed" {
+		t.Errorf("status = %+v, want error provider failed", status)
+	}
+	if events := spans[1].Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Errorf("events = %+v, want the recorded error", events)
+	}
+}
+
+func TestSetupFileExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+
+	shutdown, err := Setup(context.Background(), Options{Exporter: ExporterFile, File: path, SampleRatio: 1, ServiceName: "test"})
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	_, span := Start(context.Background(), "RateRefresher.Run")
+	End(span, nil)
+	// shutdown flushes the batched spans to the file
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read trace file: %v", err)
+	}
+	var exported struct {
+		Name     string
+		Resource []struct {
+			Key   string
+			Value struct{ Value any }
+		}
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(data))), &exported); err != nil {
+		t.Fatalf("trace file is not one JSON span: %v\n%s", err, data)
+	}
+	if exported.Name != "RateRefresher.Run" {
+		t.Errorf("exported span %q, want RateRefresher.Run", exported.Name)
+	}
+	service := ""
+	for _, kv := range exported.Resource {
+		if kv.Key == "service.name" {
+			service, _ = kv.Value.Value.(string)
+		}
+	}
+	if service != "test" {
+		t.Errorf("service.name = %q, want test", service)
+	}
+}
+
+func TestSetupNoneDoesNotRecord(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Options{Exporter: ExporterNone})
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
@@ -2,41 +2,272 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"sync"
 	"time"
 
+	currencyconstants "github.com/ItsDee25/exchange-rate-service/internal/constants/currency"
 	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
 	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type CurrencyUsecase struct {
 	currencyRepo domain.ICurrencyRepository
+	pivots       []string
+	roundingMode domain.RoundingMode
+	registry     domain.ICurrencyRegistry
+	logger       *slog.Logger
 }
 
-func NewCurrencyUsecase(r domain.ICurrencyRepository) *CurrencyUsecase {
+func NewCurrencyUsecase(r domain.ICurrencyRepository, registry domain.ICurrencyRegistry) *CurrencyUsecase {
 	return &CurrencyUsecase{
 		currencyRepo: r,
+		registry:     registry,
+		roundingMode: domain.RoundHalfEven,
+		logger:       slog.Default().With("component", "CurrencyUsecase"),
 	}
 }
 
-func (u *CurrencyUsecase) GetConvertedCurrency(ctx context.Context, from, to, date string, amount float64) (float64, error) {
+func (u *CurrencyUsecase) WithLogger(logger *slog.Logger) *CurrencyUsecase {
+	u.logger = logger.With("component", "CurrencyUsecase")
+	return u
+}
+
+// WithRoundingMode sets how converted amounts are rounded to the target currency's minor units.
+func (u *CurrencyUsecase) WithRoundingMode(mode domain.RoundingMode) *CurrencyUsecase {
+	u.roundingMode = mode
+	return u
+}
+
+// WithTriangulation enables deriving missing pairs from inverse rates and through the given pivot currencies, tried in order.
+func (u *CurrencyUsecase) WithTriangulation(pivots []string) *CurrencyUsecase {
+	u.pivots = pivots
+	return u
+}
+
+func (u *CurrencyUsecase) GetConvertedCurrency(ctx context.Context, from, to, date string, amount domain.Decimal, maxAge time.Duration) (conversion domain.Conversion, err error) {
 	if date == "" {
 		date = time.Now().Format(constants.DateLayout)
 	}
-	exchangeRate, err := u.GetExchangeRate(ctx, from, to, date)
+	ctx, span := tracing.Start(ctx, "CurrencyUsecase.GetConvertedCurrency", tracing.RateAttrs(from, to, date)...)
+	defer func() { tracing.End(span, err) }()
+
+	exchangeRate, err := u.GetExchangeRate(ctx, from, to, date, maxAge)
+	if err != nil {
+		return domain.Conversion{}, err
+	}
+	return u.convert(ctx, from, to, amount, exchangeRate)
+}
+
+// IsSupportedCurrency reports whether code is enabled in the currency registry
+func (u *CurrencyUsecase) IsSupportedCurrency(ctx context.Context, code string) bool {
+	return u.registry.IsEnabled(ctx, code)
+}
+
+// minorUnits returns the registered minor units of code, or the ISO 4217 default
+func (u *CurrencyUsecase) minorUnits(ctx context.Context, code string) int32 {
+	if currency, ok := u.registry.Lookup(ctx, code); ok {
+		return currency.MinorUnits
+	}
+	return currencyconstants.GetMinorUnits(code)
+}
+
+func (u *CurrencyUsecase) convert(ctx context.Context, from, to string, amount domain.Decimal, exchangeRate domain.ExchangeRate) (domain.Conversion, error) {
+	rate, err := domain.NewDecimalFromFloat(exchangeRate.Rate)
 	if err != nil {
-		return 0, err
+		return domain.Conversion{}, fmt.Errorf("invalid exchange rate %v: %w", exchangeRate.Rate, err)
+	}
+	converted := amount.Mul(rate).Round(u.minorUnits(ctx, to), u.roundingMode)
+
+	return domain.Conversion{
+		Amount:       domain.Money{Amount: amount, Currency: from},
+		Converted:    domain.Money{Amount: converted, Currency: to},
+		ExchangeRate: exchangeRate,
+	}, nil
+}
+
+// BatchConvert converts every item, resolving each distinct rate once. Cache and db lookups are batched,
+// keys missing from both fall back to the single-rate path (provider fetch and triangulation), run concurrently.
+func (u *CurrencyUsecase) BatchConvert(ctx context.Context, req []domain.ConversionRequest) []domain.ConversionResult {
+	ctx, span := tracing.Start(ctx, "CurrencyUsecase.BatchConvert", attribute.Int("items", len(req)))
+	defer span.End()
+
+	today := time.Now().Format(constants.DateLayout)
+	keys := make([]domain.RateKeyRequest, 0, len(req))
+	for i := range req {
+		if req[i].Date == "" {
+			req[i].Date = today
+		}
+		if req[i].From != req[i].To {
+			keys = append(keys, domain.RateKeyRequest{From: req[i].From, To: req[i].To, Date: req[i].Date})
+		}
+	}
+
+	stored, err := u.currencyRepo.GetRates(ctx, keys)
+	if err != nil {
+		u.logger.WarnContext(ctx, "failed to batch get rates, falling back to single lookups", "error", err)
+	}
+
+	type rateResult struct {
+		rate domain.ExchangeRate
+		err  error
 	}
-	return amount * exchangeRate, nil
+	resolved := make(map[domain.RateKeyRequest]rateResult, len(keys))
+	misses := make([]domain.RateKeyRequest, 0)
+	for _, key := range keys {
+		if _, ok := resolved[key]; ok {
+			continue
+		}
+		if rate, ok := stored[key]; ok {
+			resolved[key] = rateResult{rate: domain.ExchangeRate{RateKey: rate, Method: domain.RateMethodDirect}}
+			continue
+		}
+		// claim the key so a duplicate is not resolved twice
+		resolved[key] = rateResult{}
+		misses = append(misses, key)
+	}
+
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	sem := make(chan struct{}, currencyconstants.BatchConversionConcurrency)
+	for _, key := range misses {
+		wg.Add(1)
+		go func(key domain.RateKeyRequest) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			rate, err := u.GetExchangeRate(ctx, key.From, key.To, key.Date, 0)
+			mu.Lock()
+			defer mu.Unlock()
+			resolved[key] = rateResult{rate: rate, err: err}
+		}(key)
+	}
+	wg.Wait()
+
+	results := make([]domain.ConversionResult, len(req))
+	for i, item := range req {
+		results[i].Request = item
+		key := domain.RateKeyRequest{From: item.From, To: item.To, Date: item.Date}
+
+		var exchangeRate domain.ExchangeRate
+		if item.From == item.To {
+			exchangeRate, _ = u.GetExchangeRate(ctx, item.From, item.To, item.Date, 0)
+		} else if res := resolved[key]; res.err != nil {
+			results[i].Err = res.err
+			continue
+		} else {
+			exchangeRate = res.rate
+		}
+		results[i].Conversion, results[i].Err = u.convert(ctx, item.From, item.To, item.Amount, exchangeRate)
+	}
+	return results
 }
 
-func (u *CurrencyUsecase) GetExchangeRate(ctx context.Context, from, to, date string) (float64, error) {
+// GetExchangeRate returns the direct rate, or derives it when triangulation is enabled. With a positive maxAge,
+// the direct rate and every leg of a derived one are no older than maxAge.
+func (u *CurrencyUsecase) GetExchangeRate(ctx context.Context, from, to, date string, maxAge time.Duration) (exchangeRate domain.ExchangeRate, err error) {
 	if date == "" {
 		date = time.Now().Format(constants.DateLayout)
 	}
+	ctx, span := tracing.Start(ctx, "CurrencyUsecase.GetExchangeRate", tracing.RateAttrs(from, to, date)...)
+	defer func() {
+		span.SetAttributes(attribute.String("method", string(exchangeRate.Method)))
+		tracing.End(span, err)
+	}()
+
 	if from == to {
-		return 1, nil
+		return domain.ExchangeRate{
+			RateKey: domain.RateKey{
+				RateKeyRequest: domain.RateKeyRequest{From: from, To: to, Date: date},
+				Rate:           1,
+			},
+			Method: domain.RateMethodIdentity,
+		}, nil
+	}
+
+	rate, err := u.currencyRepo.GetRate(ctx, from, to, date, maxAge)
+	if err == nil {
+		return domain.ExchangeRate{RateKey: rate, Method: domain.RateMethodDirect}, nil
+	}
+	if len(u.pivots) == 0 {
+		return domain.ExchangeRate{}, err
+	}
+
+	return u.deriveRate(ctx, from, to, date, maxAge, err)
+}
+
+// deriveRate tries the inverse of the stored opposite-direction pair, then each pivot currency in order.
+// Every candidate leg is read from the cache and dynamo in one batch, the providers are not called.
+func (u *CurrencyUsecase) deriveRate(ctx context.Context, from, to, date string, maxAge time.Duration, directErr error) (domain.ExchangeRate, error) {
+	request := domain.RateKeyRequest{From: from, To: to, Date: date}
+
+	candidates := []domain.RateKeyRequest{{From: to, To: from, Date: date}}
+	for _, pivot := range u.pivots {
+		if pivot == from || pivot == to {
+			continue
+		}
+		candidates = append(candidates,
+			domain.RateKeyRequest{From: from, To: pivot, Date: date},
+			domain.RateKeyRequest{From: pivot, To: from, Date: date},
+			domain.RateKeyRequest{From: pivot, To: to, Date: date},
+			domain.RateKeyRequest{From: to, To: pivot, Date: date},
+		)
+	}
+	stored, err := u.currencyRepo.GetRates(ctx, candidates)
+	if err != nil {
+		// a partial failure still returns the legs that were read
+		u.logger.WarnContext(ctx, "failed to read some legs of a derived rate", "from", from, "to", to, "date", date, "error", err)
+	}
+	lookup := func(from, to string) (domain.RateKey, bool) {
+		rate, ok := stored[domain.RateKeyRequest{From: from, To: to, Date: date}]
+		if !ok || rate.Rate <= 0 || (maxAge > 0 && rate.OlderThan(maxAge)) {
+			return domain.RateKey{}, false
+		}
+		return rate, true
 	}
 
-	return u.currencyRepo.GetRate(ctx, from, to, date)
+	if inverse, ok := lookup(to, from); ok {
+		leg := domain.RateLeg{RateKey: inverse, Inverted: true}
+		return domain.ExchangeRate{
+			RateKey: domain.RateKey{RateKeyRequest: request, Rate: leg.EffectiveRate()},
+			Method:  domain.RateMethodInverse,
+			Legs:    []domain.RateLeg{leg},
+		}, nil
+	}
 
+	for _, pivot := range u.pivots {
+		if pivot == from || pivot == to {
+			continue
+		}
+		first, ok := getLeg(lookup, from, pivot)
+		if !ok {
+			continue
+		}
+		second, ok := getLeg(lookup, pivot, to)
+		if !ok {
+			continue
+		}
+		return domain.ExchangeRate{
+			RateKey: domain.RateKey{RateKeyRequest: request, Rate: first.EffectiveRate() * second.EffectiveRate()},
+			Method:  domain.RateMethodTriangulated,
+			Legs:    []domain.RateLeg{first, second},
+		}, nil
+	}
+
+	return domain.ExchangeRate{}, fmt.Errorf("no direct, inverse or triangulated rate for %s to %s on %s: %w", from, to, date, directErr)
+}
+
+// getLeg returns the stored rate for the pair, falling back to the inverse of the opposite-direction pair.
+func getLeg(lookup func(from, to string) (domain.RateKey, bool), from, to string) (domain.RateLeg, bool) {
+	if rate, ok := lookup(from, to); ok {
+		return domain.RateLeg{RateKey: rate}, true
+	}
+	if inverse, ok := lookup(to, from); ok {
+		return domain.RateLeg{RateKey: inverse, Inverted: true}, true
+	}
+	return domain.RateLeg{}, false
 }
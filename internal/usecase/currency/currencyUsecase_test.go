@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+const testDate = "2024-06-01"
+
+// stubRepository serves stored rates, and provider rates through GetRate, counting the calls
+type stubRepository struct {
+	mu       sync.Mutex
+	stored   map[domain.RateKeyRequest]domain.RateKey
+	provider map[domain.RateKeyRequest]float64
+	// queried is what QueryRange returns
+	queried []domain.RateKey
+	// fetchDelay makes every provider fetch take this long
+	fetchDelay time.Duration
+
+	getRateCalls  int
+	getRatesCalls int
+	fetches       int
+}
+
+func newStubRepository() *stubRepository {
+	return &stubRepository{
+		stored:   map[domain.RateKeyRequest]domain.RateKey{},
+		provider: map[domain.RateKeyRequest]float64{},
+	}
+}
+
+func (r *stubRepository) store(from, to string, rate float64, updatedAt time.Time) {
+	key := domain.RateKeyRequest{From: from, To: to, Date: testDate}
+	r.stored[key] = domain.RateKey{RateKeyRequest: key, Rate: rate, Provenance: domain.Provenance{UpdatedAt: updatedAt}}
+}
+
+func (r *stubRepository) GetRate(ctx context.Context, from, to, date string, maxAge time.Duration) (domain.RateKey, error) {
+	key := domain.RateKeyRequest{From: from, To: to, Date: date}
+	r.mu.Lock()
+	r.getRateCalls++
+	stored, ok := r.stored[key]
+	r.mu.Unlock()
+	if ok && (maxAge <= 0 || !stored.OlderThan(maxAge)) {
+		stored.Source = domain.RateSourceDynamo
+		return stored, nil
+	}
+
+	time.Sleep(r.fetchDelay)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetches++
+	rate, ok := r.provider[key]
+	if !ok {
+		return domain.RateKey{}, &domain.RateUnavailableError{RateKeyRequest: key}
+	}
+	return domain.RateKey{RateKeyRequest: key, Rate: rate, Source: domain.RateSourceProvider}, nil
+}
+
+func (r *stubRepository) GetRates(ctx context.Context, req []domain.RateKeyRequest) (map[domain.RateKeyRequest]domain.RateKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.getRatesCalls++
+	result := make(map[domain.RateKeyRequest]domain.RateKey)
+	for _, key := range req {
+		if rate, ok := r.stored[key]; ok {
+			result[key] = rate
+		}
+	}
+	return result, nil
+}
+
+func (r *stubRepository) QueryRange(ctx context.Context, from, to, start, end string) ([]domain.RateKey, error) {
+	return r.queried, nil
+}
+
+// stubRegistry enables every currency
+type stubRegistry struct{}
+
+func (stubRegistry) Lookup(ctx context.Context, code string) (domain.Currency, bool) {
+	return domain.Currency{}, false
+}
+func (stubRegistry) IsEnabled(ctx context.Context, code string) bool { return true }
+func (stubRegistry) List(ctx context.Context) []domain.Currency      { return nil }
+func (stubRegistry) EnabledPairs(ctx context.Context) [][2]string    { return nil }
+
+func TestGetExchangeRateDerivesFromStoredRatesOnly(t *testing.T) {
+	repo := newStubRepository()
+	// GBP→JPY is neither stored nor served, both legs through USD are stored
+	repo.store("USD", "GBP", 0.8, time.Now())
+	repo.store("USD", "JPY", 150, time.Now())
+	u := NewCurrencyUsecase(repo, stubRegistry{}).WithTriangulation([]string{"EUR", "USD"})
+
+	rate, err := u.GetExchangeRate(context.Background(), "GBP", "JPY", testDate, 0)
+	if err != nil {
+		t.Fatalf("GetExchangeRate: %v", err)
+	}
+	if rate.Method != domain.RateMethodTriangulated || rate.Rate != 150/0.8 {
+		t.Errorf("rate = %v (%s), want %v triangulated", rate.Rate, rate.Method, 150/0.8)
+	}
+	if len(rate.Legs) != 2 || !rate.Legs[0].Inverted || rate.Legs[1].Inverted {
+		t.Errorf("legs = %+v, want an inverted USD→GBP leg then USD→JPY", rate.Legs)
+	}
+	// only the direct lookup may reach the provider, every leg is read in one batch
+	if repo.fetches != 1 || repo.getRateCalls != 1 || repo.getRatesCalls != 1 {
+		t.Errorf("fetches = %d, GetRate calls = %d, GetRates calls = %d, want 1, 1, 1", repo.fetches, repo.getRateCalls, repo.getRatesCalls)
+	}
+}
+
+func TestGetExchangeRateInverse(t *testing.T) {
+	repo := newStubRepository()
+	repo.store("USD", "GBP", 0.8, time.Now())
+	u := NewCurrencyUsecase(repo, stubRegistry{}).WithTriangulation([]string{"EUR"})
+
+	rate, err := u.GetExchangeRate(context.Background(), "GBP", "USD", testDate, 0)
+	if err != nil {
+		t.Fatalf("GetExchangeRate: %v", err)
+	}
+	if rate.Method != domain.RateMethodInverse || rate.Rate != 1/0.8 {
+		t.Errorf("rate = %v (%s), want %v inverse", rate.Rate, rate.Method, 1/0.8)
+	}
+}
+
+func TestGetExchangeRateSkipsLegsOlderThanMaxAge(t *testing.T) {
+	repo := newStubRepository()
+	repo.store("USD", "GBP", 0.8, time.Now().Add(-time.Hour))
+	u := NewCurrencyUsecase(repo, stubRegistry{}).WithTriangulation([]string{"EUR"})
+
+	_, err := u.GetExchangeRate(context.Background(), "GBP", "USD", testDate, time.Minute)
+	if !errors.Is(err, domain.ErrRateUnavailable) {
+		t.Errorf("err = %v, want ErrRateUnavailable", err)
+	}
+}
+
+func TestBatchConvertResolvesMissesConcurrently(t *testing.T) {
+	repo := newStubRepository()
+	repo.fetchDelay = 50 * time.Millisecond
+	currencies := []string{"EUR", "GBP", "JPY", "INR", "CHF", "AUD", "CAD", "SEK", "NOK", "DKK"}
+	req := make([]domain.ConversionRequest, 0, len(currencies)+1)
+	for i, code := range currencies {
+		repo.provider[domain.RateKeyRequest{From: "USD", To: code, Date: testDate}] = float64(i + 1)
+		req = append(req, domain.ConversionRequest{From: "USD", To: code, Date: testDate, Amount: mustDecimal(t, "10")})
+	}
+	// a duplicate key is resolved once
+	req = append(req, req[0])
+	u := NewCurrencyUsecase(repo, stubRegistry{})
+
+	start := time.Now()
+	results := u.BatchConvert(context.Background(), req)
+	elapsed := time.Since(start)
+
+	for i, res := range results {
+		if res.Err != nil {
+			t.Fatalf("item %d: %v", i, res.Err)
+		}
+	}
+	if got := results[1].Conversion.Converted.Amount.String(); got != "20.00" {
+		t.Errorf("converted = %s, want 20.00", got)
+	}
+	if repo.fetches != len(currencies) {
+		t.Errorf("fetches = %d, want %d", repo.fetches, len(currencies))
+	}
+	// sequential fetches would take 500ms
+	if elapsed >= time.Duration(len(currencies))*repo.fetchDelay {
+		t.Errorf("batch took %s, misses were not resolved concurrently", elapsed)
+	}
+}
+
+func mustDecimal(t *testing.T, raw string) domain.Decimal {
+	t.Helper()
+	d, err := domain.NewDecimalFromString(raw)
+	if err != nil {
+		t.Fatalf("NewDecimalFromString(%q): %v", raw, err)
+	}
+	return d
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	currencyconstants "github.com/ItsDee25/exchange-rate-service/internal/constants/currency"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+// maxMinorUnits is the largest number of decimal places used by an ISO 4217 currency
+const maxMinorUnits = 4
+
+type CurrencyRegistryUsecase struct {
+	registry domain.ICurrencyRegistryRepository
+}
+
+func NewCurrencyRegistryUsecase(registry domain.ICurrencyRegistryRepository) *CurrencyRegistryUsecase {
+	return &CurrencyRegistryUsecase{registry: registry}
+}
+
+func (u *CurrencyRegistryUsecase) ListCurrencies(ctx context.Context) []domain.Currency {
+	return u.registry.List(ctx)
+}
+
+// SeedCurrencies registers the given ISO 4217 codes, enabled, unless they are registered already
+func (u *CurrencyRegistryUsecase) SeedCurrencies(ctx context.Context, codes []string) error {
+	currencies := make([]domain.Currency, 0, len(codes))
+	for _, code := range codes {
+		currency, err := u.apply(isoCurrency(code), domain.CurrencyUpdate{Code: code})
+		if err != nil {
+			return err
+		}
+		currencies = append(currencies, currency)
+	}
+	return u.registry.Seed(ctx, currencies)
+}
+
+func (u *CurrencyRegistryUsecase) UpdateCurrency(ctx context.Context, update domain.CurrencyUpdate) (domain.Currency, error) {
+	update.Code = strings.ToUpper(strings.TrimSpace(update.Code))
+	current, ok := u.registry.Lookup(ctx, update.Code)
+	if !ok {
+		current = isoCurrency(update.Code)
+	}
+	currency, err := u.apply(current, update)
+	if err != nil {
+		return domain.Currency{}, err
+	}
+	if err := u.registry.Put(ctx, currency); err != nil {
+		return domain.Currency{}, err
+	}
+	return currency, nil
+}
+
+// apply sets the non nil fields of update on current and validates the result
+func (u *CurrencyRegistryUsecase) apply(current domain.Currency, update domain.CurrencyUpdate) (domain.Currency, error) {
+	if update.Name != nil {
+		current.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.NumericCode != nil {
+		current.NumericCode = strings.TrimSpace(*update.NumericCode)
+	}
+	if update.MinorUnits != nil {
+		current.MinorUnits = *update.MinorUnits
+	}
+	if update.Enabled != nil {
+		current.Enabled = *update.Enabled
+	}
+	current.UpdatedAt = time.Now().UTC()
+
+	if !isAlphaCode(current.Code) {
+		return domain.Currency{}, fmt.Errorf("%w: code %q must be 3 letters", domain.ErrInvalidCurrency, current.Code)
+	}
+	if current.Name == "" {
+		return domain.Currency{}, fmt.Errorf("%w: %s is not a known ISO 4217 currency, a name is required", domain.ErrInvalidCurrency, current.Code)
+	}
+	if current.MinorUnits < 0 || current.MinorUnits > maxMinorUnits {
+		return domain.Currency{}, fmt.Errorf("%w: minor units must be between 0 and %d", domain.ErrInvalidCurrency, maxMinorUnits)
+	}
+	return current, nil
+}
+
+// isoCurrency returns an enabled currency with the ISO 4217 metadata of code, without a name if code is unknown
+func isoCurrency(code string) domain.Currency {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	iso := currencyconstants.ISO4217[code]
+	return domain.Currency{
+		Code:        code,
+		Name:        iso.Name,
+		NumericCode: iso.NumericCode,
+		MinorUnits:  currencyconstants.GetMinorUnits(code),
+		Enabled:     true,
+	}
+}
+
+func isAlphaCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	repository "github.com/ItsDee25/exchange-rate-service/internal/repository/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func newTestRegistryUsecase(t *testing.T, codes ...string) (*CurrencyRegistryUsecase, *repository.CurrencyRegistry, *mocks.DynamoFake) {
+	t.Helper()
+	fake := mocks.NewDynamoFake()
+	registry := repository.NewCurrencyRegistry(fake)
+	u := NewCurrencyRegistryUsecase(registry)
+	if err := u.SeedCurrencies(context.Background(), codes); err != nil {
+		t.Fatalf("SeedCurrencies: %v", err)
+	}
+	return u, registry, fake
+}
+
+func TestSeedCurrenciesUsesISOMetadata(t *testing.T) {
+	_, registry, _ := newTestRegistryUsecase(t, "usd", " JPY ", "KWD")
+
+	for code, minorUnits := range map[string]int32{"USD": 2, "JPY": 0, "KWD": 3} {
+		c, ok := registry.Lookup(context.Background(), code)
+		if !ok || c.Name == "" || c.NumericCode == "" || c.MinorUnits != minorUnits || !c.Enabled {
+			t.Errorf("Lookup(%s) = %+v, %v, want it enabled with its ISO 4217 metadata and %d minor units", code, c, ok, minorUnits)
+		}
+	}
+}
+
+func TestSeedCurrenciesRejectsInvalidCodes(t *testing.T) {
+	for _, codes := range [][]string{{"USD", "US"}, {"XYZ"}, {"U$D"}} {
+		fake := mocks.NewDynamoFake()
+		err := NewCurrencyRegistryUsecase(repository.NewCurrencyRegistry(fake)).SeedCurrencies(context.Background(), codes)
+		if !errors.Is(err, domain.ErrInvalidCurrency) {
+			t.Errorf("SeedCurrencies(%v) err = %v, want ErrInvalidCurrency", codes, err)
+		}
+		if len(fake.Items()) != 0 {
+			t.Errorf("SeedCurrencies(%v) stored %d currencies, want none", codes, len(fake.Items()))
+		}
+	}
+}
+
+func TestUpdateCurrency(t *testing.T) {
+	tests := []struct {
+		name   string
+		update domain.CurrencyUpdate
+		want   domain.Currency
+	}{
+		{
+			name:   "new ISO 4217 currency defaults to its metadata, enabled",
+			update: domain.CurrencyUpdate{Code: " cad "},
+			want:   domain.Currency{Code: "CAD", Name: "Canadian Dollar", NumericCode: "124", MinorUnits: 2, Enabled: true},
+		},
+		{
+			name:   "unknown code with a name",
+			update: domain.CurrencyUpdate{Code: "XTS", Name: ptr(" Test Currency "), MinorUnits: ptr(int32(4))},
+			want:   domain.Currency{Code: "XTS", Name: "Test Currency", MinorUnits: 4, Enabled: true},
+		},
+		{
+			name:   "only the given fields change",
+			update: domain.CurrencyUpdate{Code: "JPY", MinorUnits: ptr(int32(2))},
+			want:   domain.Currency{Code: "JPY", Name: "Yen", NumericCode: "392", MinorUnits: 2, Enabled: true},
+		},
+		{
+			name:   "zero minor units",
+			update: domain.CurrencyUpdate{Code: "USD", MinorUnits: ptr(int32(0))},
+			want:   domain.Currency{Code: "USD", Name: "US Dollar", NumericCode: "840", MinorUnits: 0, Enabled: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, registry, _ := newTestRegistryUsecase(t, "USD", "JPY")
+
+			got, err := u.UpdateCurrency(context.Background(), tt.update)
+			if err != nil {
+				t.Fatalf("UpdateCurrency: %v", err)
+			}
+			if got.UpdatedAt.IsZero() {
+				t.Error("UpdatedAt not set")
+			}
+			got.UpdatedAt = tt.want.UpdatedAt
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UpdateCurrency = %+v, want %+v", got, tt.want)
+			}
+			if stored, _ := registry.Lookup(context.Background(), tt.want.Code); stored.Name != tt.want.Name || stored.MinorUnits != tt.want.MinorUnits {
+				t.Errorf("registry has %+v, want the update applied", stored)
+			}
+		})
+	}
+}
+
+func TestUpdateCurrencyRejectedLeavesRegistryUnchanged(t *testing.T) {
+	tests := []struct {
+		name   string
+		update domain.CurrencyUpdate
+	}{
+		{"code of 2 letters", domain.CurrencyUpdate{Code: "EU", Name: ptr("Euro")}},
+		{"code of 4 letters", domain.CurrencyUpdate{Code: "EURO", Name: ptr("Euro")}},
+		{"code with a digit", domain.CurrencyUpdate{Code: "EU1", Name: ptr("Euro")}},
+		{"unknown code without a name", domain.CurrencyUpdate{Code: "XTS"}},
+		{"name cleared", domain.CurrencyUpdate{Code: "EUR", Name: ptr("  ")}},
+		{"negative minor units", domain.CurrencyUpdate{Code: "EUR", MinorUnits: ptr(int32(-1))}},
+		{"too many minor units", domain.CurrencyUpdate{Code: "EUR", MinorUnits: ptr(int32(maxMinorUnits + 1)), Enabled: ptr(false)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, registry, fake := newTestRegistryUsecase(t, "USD", "EUR")
+			before, items := registry.List(context.Background()), fake.Items()
+
+			if _, err := u.UpdateCurrency(context.Background(), tt.update); !errors.Is(err, domain.ErrInvalidCurrency) {
+				t.Fatalf("UpdateCurrency err = %v, want ErrInvalidCurrency", err)
+			}
+			if after := registry.List(context.Background()); !reflect.DeepEqual(after, before) {
+				t.Errorf("registry changed to %+v by a rejected update", after)
+			}
+			if !reflect.DeepEqual(fake.Items(), items) {
+				t.Error("stored currencies changed by a rejected update")
+			}
+		})
+	}
+}
+
+func TestDisablingPivotCurrency(t *testing.T) {
+	// USD is the first default triangulation pivot, disabling it only removes it from the served pairs
+	ctx := context.Background()
+	u, registry, fake := newTestRegistryUsecase(t, "USD", "EUR", "INR")
+
+	disabled, err := u.UpdateCurrency(ctx, domain.CurrencyUpdate{Code: "USD", Enabled: ptr(false)})
+	if err != nil {
+		t.Fatalf("UpdateCurrency: %v", err)
+	}
+	if disabled.Enabled || disabled.Name != "US Dollar" {
+		t.Errorf("UpdateCurrency = %+v, want USD disabled and otherwise unchanged", disabled)
+	}
+	if registry.IsEnabled(ctx, "USD") {
+		t.Error("USD still enabled")
+	}
+	if _, ok := registry.Lookup(ctx, "USD"); !ok {
+		t.Error("disabled USD no longer registered")
+	}
+	if got, want := registry.EnabledPairs(ctx), [][2]string{{"EUR", "INR"}, {"INR", "EUR"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EnabledPairs() = %v, want %v", got, want)
+	}
+
+	// a restart seeding the configured currencies keeps it disabled
+	restarted := repository.NewCurrencyRegistry(fake)
+	if err := NewCurrencyRegistryUsecase(restarted).SeedCurrencies(ctx, []string{"USD", "EUR", "INR"}); err != nil {
+		t.Fatalf("SeedCurrencies: %v", err)
+	}
+	if restarted.IsEnabled(ctx, "USD") {
+		t.Error("USD enabled again by the seed of a restart")
+	}
+}
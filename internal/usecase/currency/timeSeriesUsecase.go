@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	currencyconstants "github.com/ItsDee25/exchange-rate-service/internal/constants/currency"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
+	"github.com/ItsDee25/exchange-rate-service/pkg/timeutil"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GetTimeSeries returns the rates of a pair for every date between start and end (inclusive).
+// Dates missing from storage are filled through GetRate, which fetches and persists them, and are marked
+// backfilled when GetRate fetched them from a provider.
+func (u *CurrencyUsecase) GetTimeSeries(ctx context.Context, from, to, start, end string) (series domain.TimeSeries, err error) {
+	ctx, span := tracing.Start(ctx, "CurrencyUsecase.GetTimeSeries",
+		tracing.AttrPair.String(from+"/"+to), attribute.String("start", start), attribute.String("end", end))
+	defer func() {
+		span.SetAttributes(attribute.Int("missing", len(series.Missing)))
+		tracing.End(span, err)
+	}()
+
+	dates, err := timeutil.DatesBetween(start, end)
+	if err != nil {
+		return domain.TimeSeries{}, err
+	}
+
+	stored, err := u.currencyRepo.QueryRange(ctx, from, to, start, end)
+	if err != nil {
+		return domain.TimeSeries{}, err
+	}
+	byDate := make(map[string]domain.RateKey, len(stored))
+	for _, rate := range stored {
+		byDate[rate.Date] = rate
+	}
+
+	points := make([]domain.TimeSeriesPoint, len(dates))
+	found := make([]bool, len(dates))
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	sem := make(chan struct{}, currencyconstants.TimeSeriesBackfillConcurrency)
+	for i, date := range dates {
+		if rate, ok := byDate[date]; ok {
+			points[i] = domain.TimeSeriesPoint{RateKey: rate}
+			found[i] = true
+			continue
+		}
+		wg.Add(1)
+		go func(i int, date string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			rate, err := u.currencyRepo.GetRate(ctx, from, to, date, 0)
+			if err != nil {
+				u.logger.WarnContext(ctx, "failed to backfill rate", "from", from, "to", to, "date", date, "error", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			// a rate stored after the range query ran is served from the cache or dynamo, it was not backfilled
+			points[i] = domain.TimeSeriesPoint{RateKey: rate, Backfilled: rate.Source == domain.RateSourceProvider}
+			found[i] = true
+		}(i, date)
+	}
+	wg.Wait()
+
+	series = domain.TimeSeries{
+		From:    from,
+		To:      to,
+		Start:   start,
+		End:     end,
+		Points:  make([]domain.TimeSeriesPoint, 0, len(dates)),
+		Missing: make([]string, 0),
+	}
+	for i, date := range dates {
+		if !found[i] {
+			series.Missing = append(series.Missing, date)
+			continue
+		}
+		series.Points = append(series.Points, points[i])
+	}
+	return series, nil
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+func TestGetTimeSeriesMarksOnlyProviderRatesBackfilled(t *testing.T) {
+	repo := newStubRepository()
+	key := func(date string) domain.RateKeyRequest {
+		return domain.RateKeyRequest{From: "USD", To: "INR", Date: date}
+	}
+	repo.queried = []domain.RateKey{{RateKeyRequest: key("2024-05-30"), Rate: 83.31}}
+	// stored by the refresher after the range query ran
+	repo.stored[key("2024-05-31")] = domain.RateKey{RateKeyRequest: key("2024-05-31"), Rate: 83.47, Provenance: domain.Provenance{UpdatedAt: time.Now()}}
+	repo.provider[key("2024-06-01")] = 83.12
+	u := NewCurrencyUsecase(repo, stubRegistry{})
+
+	series, err := u.GetTimeSeries(context.Background(), "USD", "INR", "2024-05-30", "2024-06-02")
+	if err != nil {
+		t.Fatalf("GetTimeSeries: %v", err)
+	}
+
+	want := map[string]bool{"2024-05-30": false, "2024-05-31": false, "2024-06-01": true}
+	if len(series.Points) != len(want) {
+		t.Fatalf("points = %+v, want %d", series.Points, len(want))
+	}
+	for _, point := range series.Points {
+		if point.Backfilled != want[point.Date] {
+			t.Errorf("%s backfilled = %v, want %v", point.Date, point.Backfilled, want[point.Date])
+		}
+	}
+	if len(series.Missing) != 1 || series.Missing[0] != "2024-06-02" {
+		t.Errorf("missing = %v, want [2024-06-02]", series.Missing)
+	}
+}
@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CacheWarmer loads the stored rates of recent days into the cache once on startup, so a new instance
+// does not serve every request from dynamo or the provider until the first refresher run
+type CacheWarmer struct {
+	repo     domain.IRefresherRepository
+	registry domain.ICurrencyRegistry
+	days     int
+	timeout  time.Duration
+	logger   *slog.Logger
+	done     chan struct{}
+	warmed   atomic.Bool
+}
+
+// NewCacheWarmer builds a warmer of every pair of currencies enabled in the registry. It loads the rates
+// of today and the previous days days, 0 loads today's rates only, and is bounded by timeout, the rates
+// read when it elapses are kept.
+func NewCacheWarmer(repo domain.IRefresherRepository, registry domain.ICurrencyRegistry, days int, timeout time.Duration) *CacheWarmer {
+	return &CacheWarmer{
+		repo:     repo,
+		registry: registry,
+		days:     days,
+		timeout:  timeout,
+		logger:   slog.Default().With("component", "CacheWarmer"),
+		done:     make(chan struct{}),
+	}
+}
+
+func (w *CacheWarmer) WithLogger(logger *slog.Logger) *CacheWarmer {
+	w.logger = logger.With("component", "CacheWarmer")
+	return w
+}
+
+// Start runs the warm-up once in the background, it is abandoned when ctx is done
+func (w *CacheWarmer) Start(ctx context.Context) {
+	w.logger.Info("starting cache warm-up", "days", w.days, "timeout", w.timeout.String())
+	go func() {
+		defer close(w.done)
+		defer w.warmed.Store(true)
+		w.Run(ctx)
+	}()
+}
+
+// Wait blocks until the warm-up started by Start has finished, or ctx is done
+func (w *CacheWarmer) Wait(ctx context.Context) error {
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Warmed reports whether the warm-up started by Start has finished, whether or not every rate was loaded
+func (w *CacheWarmer) Warmed() bool {
+	return w.warmed.Load()
+}
+
+// Run reads the rates of today and the previous w.days days of every enabled pair in batches and caches them
+func (w *CacheWarmer) Run(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	start := time.Now()
+
+	currencyPairs := w.registry.EnabledPairs(ctx)
+	ctx, span := tracing.Start(ctx, "CacheWarmer.Run", attribute.Int("pairs", len(currencyPairs)), attribute.Int("days", w.days))
+	defer span.End()
+
+	req := make([]domain.RateKeyRequest, 0, len(currencyPairs)*(w.days+1))
+	for day := 0; day <= w.days; day++ {
+		date := start.AddDate(0, 0, -day).Format(constants.DateLayout)
+		for _, pair := range currencyPairs {
+			req = append(req, domain.RateKeyRequest{
+				From: pair[0],
+				To:   pair[1],
+				Date: date,
+			})
+		}
+	}
+	if len(req) == 0 {
+		w.logger.WarnContext(ctx, "no enabled currency pair to warm up")
+		return
+	}
+
+	rateKeys, err := w.repo.BatchGetFromDB(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		var partial *domain.BatchPartialFailureError
+		if !errors.As(err, &partial) {
+			w.logger.ErrorContext(ctx, "failed to get rates from db", "keys", len(req), "error", err)
+			return
+		}
+		// keep the rates that were read
+		w.logger.WarnContext(ctx, "failed to get some rates from db", "error", err)
+	}
+	span.SetAttributes(attribute.Int("loaded", len(rateKeys)))
+	if err := w.repo.BatchUpdateCache(ctx, rateKeys); err != nil {
+		w.logger.ErrorContext(ctx, "failed to update cache in batch", "rates", len(rateKeys), "error", err)
+		span.RecordError(err)
+		return
+	}
+	w.logger.InfoContext(ctx, "cache warm-up completed", "rates", len(rateKeys), "keys", len(req), "duration", time.Since(start).String())
+}
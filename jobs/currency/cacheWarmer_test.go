@@ -0,0 +1,212 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	repository "github.com/ItsDee25/exchange-rate-service/internal/repository/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+)
+
+// gatedRepository holds BatchGetFromDB until release is closed or its context is done, then lets read
+// rewrite the result
+type gatedRepository struct {
+	domain.IRefresherRepository
+	started chan struct{}
+	release chan struct{}
+	read    func(rates []domain.RateKey, err error) ([]domain.RateKey, error)
+}
+
+func (r *gatedRepository) BatchGetFromDB(ctx context.Context, req []domain.RateKeyRequest) ([]domain.RateKey, error) {
+	if r.started != nil {
+		close(r.started)
+	}
+	if r.release != nil {
+		select {
+		case <-r.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	rates, err := r.IRefresherRepository.BatchGetFromDB(ctx, req)
+	if r.read != nil {
+		return r.read(rates, err)
+	}
+	return rates, err
+}
+
+var warmerRegistry = stubRegistry{currencies: []string{"USD", "EUR"}}
+
+// newWarmerRepository stores the rates of today and yesterday of every pair, returning a repository with
+// an empty cache on the same table
+func newWarmerRepository(t *testing.T) (*repository.CurrencyDynamoRepository, []domain.RateKeyRequest) {
+	t.Helper()
+	fake := mocks.NewDynamoFake()
+	fetcher := &stubFetcher{}
+	keys := make([]domain.RateKeyRequest, 0)
+	rates := make([]domain.RateKey, 0)
+	for day := 0; day <= 1; day++ {
+		date := time.Now().AddDate(0, 0, -day).Format(constants.DateLayout)
+		for _, pair := range warmerRegistry.EnabledPairs(context.Background()) {
+			key := domain.RateKeyRequest{From: pair[0], To: pair[1], Date: date}
+			keys = append(keys, key)
+			rates = append(rates, domain.RateKey{RateKeyRequest: key, Rate: 0.92, Provenance: domain.Provenance{Provider: "stub"}})
+		}
+	}
+	if err := repository.NewDynamoRepository(fake, fetcher, repository.NewLRURateCache()).BatchUpdateDB(context.Background(), rates, nil); err != nil {
+		t.Fatalf("BatchUpdateDB: %v", err)
+	}
+	return repository.NewDynamoRepository(fake, fetcher, repository.NewLRURateCache()), keys
+}
+
+// cached reports which keys the repository serves from its cache
+func cached(t *testing.T, repo *repository.CurrencyDynamoRepository, keys []domain.RateKeyRequest) map[domain.RateKeyRequest]bool {
+	t.Helper()
+	hits := make(map[domain.RateKeyRequest]bool, len(keys))
+	for _, key := range keys {
+		rate, err := repo.GetRate(context.Background(), key.From, key.To, key.Date, 0)
+		if err != nil {
+			t.Fatalf("GetRate(%+v): %v", key, err)
+		}
+		hits[key] = rate.Source == domain.RateSourceCache
+	}
+	return hits
+}
+
+func waitWarmer(t *testing.T, warmer *CacheWarmer) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := warmer.Wait(ctx); err != nil {
+		t.Fatalf("warm-up did not finish: %v", err)
+	}
+}
+
+func TestCacheWarmerWarmedFlipsOnceWarm(t *testing.T) {
+	repo, keys := newWarmerRepository(t)
+	gated := &gatedRepository{IRefresherRepository: repo, started: make(chan struct{}), release: make(chan struct{})}
+	warmer := NewCacheWarmer(gated, warmerRegistry, 1, time.Minute)
+
+	if warmer.Warmed() {
+		t.Fatal("Warmed before the warm-up started")
+	}
+	warmer.Start(context.Background())
+	<-gated.started
+	if warmer.Warmed() {
+		t.Error("Warmed while the warm-up is reading dynamo")
+	}
+	close(gated.release)
+	waitWarmer(t, warmer)
+
+	if !warmer.Warmed() {
+		t.Error("not Warmed once the warm-up finished")
+	}
+	for key, hit := range cached(t, repo, keys) {
+		if !hit {
+			t.Errorf("%+v not cached by the warm-up", key)
+		}
+	}
+}
+
+func TestCacheWarmerReadFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(rates []domain.RateKey, err error) ([]domain.RateKey, error)
+		// wantMissed is how many of the 4 stored keys are not cached
+		wantMissed int
+	}{
+		{
+			name: "total failure",
+			read: func(rates []domain.RateKey, err error) ([]domain.RateKey, error) {
+				return nil, errors.New("connection refused")
+			},
+			wantMissed: 4,
+		},
+		{
+			name: "partial failure keeps the rates read",
+			read: func(rates []domain.RateKey, err error) ([]domain.RateKey, error) {
+				last := len(rates) - 1
+				return rates[:last], &domain.BatchPartialFailureError{Op: "BatchGetFromDB", FailedReads: []domain.RateKeyRequest{rates[last].RateKeyRequest}}
+			},
+			wantMissed: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, keys := newWarmerRepository(t)
+			warmer := NewCacheWarmer(&gatedRepository{IRefresherRepository: repo, read: tt.read}, warmerRegistry, 1, time.Minute)
+
+			warmer.Start(context.Background())
+			waitWarmer(t, warmer)
+
+			if !warmer.Warmed() {
+				t.Error("not Warmed after a failed warm-up, readiness would never pass")
+			}
+			missed := 0
+			for _, hit := range cached(t, repo, keys) {
+				if !hit {
+					missed++
+				}
+			}
+			if missed != tt.wantMissed {
+				t.Errorf("%d of %d keys not cached, want %d", missed, len(keys), tt.wantMissed)
+			}
+		})
+	}
+}
+
+func TestCacheWarmerStopsWhenCancelled(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		cancel  bool
+	}{
+		{name: "context cancelled", timeout: time.Minute, cancel: true},
+		{name: "timeout elapsed", timeout: 20 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, keys := newWarmerRepository(t)
+			// never released, the read only ends with its context
+			gated := &gatedRepository{IRefresherRepository: repo, started: make(chan struct{}), release: make(chan struct{})}
+			warmer := NewCacheWarmer(gated, warmerRegistry, 1, tt.timeout)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			warmer.Start(ctx)
+			<-gated.started
+			if tt.cancel {
+				cancel()
+			}
+			waitWarmer(t, warmer)
+
+			for key, hit := range cached(t, repo, keys) {
+				if hit {
+					t.Errorf("%+v cached by an abandoned warm-up", key)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheWarmerWithoutPairs(t *testing.T) {
+	repo, _ := newWarmerRepository(t)
+	gated := &gatedRepository{IRefresherRepository: repo, started: make(chan struct{})}
+	warmer := NewCacheWarmer(gated, stubRegistry{}, 1, time.Minute)
+
+	warmer.Start(context.Background())
+	waitWarmer(t, warmer)
+
+	select {
+	case <-gated.started:
+		t.Error("dynamo read without any enabled pair")
+	default:
+	}
+	if !warmer.Warmed() {
+		t.Error("not Warmed without any pair to load")
+	}
+}
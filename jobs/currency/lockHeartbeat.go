@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+type heartbeat struct {
+	mu      sync.Mutex
+	current domain.Lease
+}
+
+func (h *heartbeat) lease() domain.Lease {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.current
+}
+
+// startHeartbeat renews the lease every interval until ctx is done, calling onLost if the lease is taken over.
+func startHeartbeat(ctx context.Context, logger *slog.Logger, locker domain.ILocker, lease domain.Lease, ttl, interval time.Duration, onLost func()) *heartbeat {
+	h := &heartbeat{current: lease}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				renewed, err := locker.Renew(ctx, h.lease(), ttl)
+				if errors.Is(err, domain.ErrLockLost) {
+					logger.WarnContext(ctx, "lost lock", "lock", lease.LockKey, "token", lease.Token)
+					onLost()
+					return
+				}
+				if err != nil {
+					// transient, the next beat retries before the lease expires
+					logger.WarnContext(ctx, "failed to renew lock", "lock", lease.LockKey, "error", err)
+					continue
+				}
+				h.mu.Lock()
+				h.current = renewed
+				h.mu.Unlock()
+			}
+		}
+	}()
+	return h
+}
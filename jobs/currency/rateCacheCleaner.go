@@ -2,37 +2,65 @@ package jobs
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
 )
 
-const cleanerFrequency = 24 * time.Hour
-
 type cacheCleaner struct {
-	cache domain.IRateCache
+	cache     domain.IRateCache
+	frequency time.Duration
+	logger    *slog.Logger
+	done      chan struct{}
+}
+
+// NewCacheCleaner builds a cleaner of the expired cache entries, running every frequency
+func NewCacheCleaner(cache domain.IRateCache, frequency time.Duration) *cacheCleaner {
+	return &cacheCleaner{
+		cache:     cache,
+		frequency: frequency,
+		logger:    slog.Default().With("component", "CacheCleaner"),
+		done:      make(chan struct{}),
+	}
 }
 
-func NewCacheCleaner(cache domain.IRateCache) *cacheCleaner {
-	return &cacheCleaner{cache: cache}
+func (c *cacheCleaner) WithLogger(logger *slog.Logger) *cacheCleaner {
+	c.logger = logger.With("component", "CacheCleaner")
+	return c
 }
 
-func (c *cacheCleaner) Start() {
-	log.Println("[CacheCleaner] Starting cache cleaner job")
-	ticker := time.NewTicker(cleanerFrequency) // Run every 24 hours
+// Start runs the cleaner every c.frequency until ctx is done
+func (c *cacheCleaner) Start(ctx context.Context) {
+	c.logger.Info("starting cache cleaner job", "frequency", c.frequency.String())
+	ticker := time.NewTicker(c.frequency)
 	go func() {
+		defer close(c.done)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				c.Run()
+				c.Run(context.WithoutCancel(ctx))
+			case <-ctx.Done():
+				c.logger.Info("stopping cache cleaner job")
+				return
 			}
 		}
 	}()
 }
 
-func (c *cacheCleaner) Run() {
-	log.Printf("Running cache cleaner at time %v", time.Now().Format("2006-01-02 15:04:05"))
-	c.cache.ScanAndDeleteExipred(context.Background())
-	log.Printf("[CacheCleaner] Cache cleaner completed successfully at time %v", time.Now().Format("2006-01-02 15:04:05"))
+// Wait blocks until the job started by Start has stopped, or ctx is done
+func (c *cacheCleaner) Wait(ctx context.Context) error {
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *cacheCleaner) Run(ctx context.Context) {
+	start := time.Now()
+	c.cache.ScanAndDeleteExipred(ctx)
+	c.logger.InfoContext(ctx, "cache cleaner completed", "duration", time.Since(start).String())
 }
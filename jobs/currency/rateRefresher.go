@@ -2,100 +2,169 @@ package jobs
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	"github.com/ItsDee25/exchange-rate-service/internal/metrics"
+	"github.com/ItsDee25/exchange-rate-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-const (
-	lockId        = "rate_refresher_lock"
-	lockTTL       = 25 * time.Minute
-	refresherFreq = 30 * time.Minute
-)
+const lockId = "rate_refresher_lock"
 
 type RateRefresher struct {
-	repo          domain.IRefresherRepository
-	fetcher       domain.IRateFetcher
-	locker        domain.ILocker
-	currencyPairs [][2]string
+	repo      domain.IRefresherRepository
+	fetcher   domain.IRateSourceFetcher
+	locker    domain.ILocker
+	registry  domain.ICurrencyRegistry
+	frequency time.Duration
+	lockTTL   time.Duration
+	// concurrency bounds the provider calls a run makes at once
+	concurrency int
+	logger      *slog.Logger
+	done        chan struct{}
+	// lastSuccess is the unix nano time of the last run that stored or synced rates
+	lastSuccess atomic.Int64
 }
 
-func NewRateRefresher(repo domain.IRefresherRepository, fetcher domain.IRateFetcher, locker domain.ILocker, pairs [][2]string) *RateRefresher {
+// NewRateRefresher builds a refresher of every pair of currencies enabled in the registry, running every
+// frequency under a lock lease of lockTTL, renewed every third of it, fetching at most concurrency pairs at once
+func NewRateRefresher(repo domain.IRefresherRepository, fetcher domain.IRateSourceFetcher, locker domain.ILocker, registry domain.ICurrencyRegistry, frequency, lockTTL time.Duration, concurrency int) *RateRefresher {
 	return &RateRefresher{
-		repo:          repo,
-		fetcher:       fetcher,
-		locker:        locker,
-		currencyPairs: pairs,
+		repo:        repo,
+		fetcher:     fetcher,
+		locker:      locker,
+		registry:    registry,
+		frequency:   frequency,
+		lockTTL:     lockTTL,
+		concurrency: concurrency,
+		logger:      slog.Default().With("component", "RateRefresher"),
+		done:        make(chan struct{}),
 	}
 }
 
-func (r *RateRefresher) Start() {
-	log.Println("[RateRefresher] Starting rate refresher job")
-	ticker := time.NewTicker(refresherFreq)
+func (r *RateRefresher) WithLogger(logger *slog.Logger) *RateRefresher {
+	r.logger = logger.With("component", "RateRefresher")
+	return r
+}
+
+// LastSuccess returns when a run last stored fresh rates or synced the cache from dynamo, zero if none did
+func (r *RateRefresher) LastSuccess() time.Time {
+	if at := r.lastSuccess.Load(); at > 0 {
+		return time.Unix(0, at)
+	}
+	return time.Time{}
+}
+
+// Start runs the refresher every r.frequency until ctx is done. A refresh in flight when ctx is done
+// is not cancelled, Wait blocks until it finishes.
+func (r *RateRefresher) Start(ctx context.Context) {
+	r.logger.Info("starting rate refresher job", "frequency", r.frequency.String())
+	ticker := time.NewTicker(r.frequency)
 	go func() {
+		defer close(r.done)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				r.Run()
+				r.Run(context.WithoutCancel(ctx))
+			case <-ctx.Done():
+				r.logger.Info("stopping rate refresher job")
+				return
 			}
 		}
 	}()
 }
 
-func (r *RateRefresher) Run() {
-	log.Printf("Running rate refresher for pairs: %v\n at time %v", r.currencyPairs, time.Now().Format("2006-01-02 15:04:05"))
-	ctx := context.Background()
+// Wait blocks until the job started by Start has stopped, or ctx is done
+func (r *RateRefresher) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run refreshes today's rates of every enabled pair. The instance holding the lock fetches the pairs not
+// stored within the last r.frequency, stores them under its fencing token and caches every fresh rate,
+// the others sync their cache from dynamo.
+func (r *RateRefresher) Run(ctx context.Context) {
+	currencyPairs := r.registry.EnabledPairs(ctx)
+	r.logger.InfoContext(ctx, "running rate refresher", "pairs", len(currencyPairs))
 	today := time.Now().Format("2006-01-02")
-	locked, err := r.locker.AcquireLock(ctx, lockId, lockTTL)
+	start := time.Now()
+	mode := metrics.RefreshModeCacheSync
+	ctx, span := tracing.Start(ctx, "RateRefresher.Run", attribute.Int("pairs", len(currencyPairs)), tracing.AttrDate.String(today))
+	defer func() {
+		metrics.RefresherRuns.WithLabelValues(mode).Inc()
+		metrics.RefresherDuration.WithLabelValues(mode).Observe(time.Since(start).Seconds())
+		span.SetAttributes(attribute.String("mode", mode))
+		span.End()
+	}()
+	lease, locked, err := r.locker.AcquireLock(ctx, lockId, r.lockTTL)
 	if err != nil {
-		log.Printf("Failed to acquire lock: %v", err)
+		mode = metrics.RefreshModeLockError
+		r.logger.ErrorContext(ctx, "failed to acquire lock", "error", err)
+		span.RecordError(err)
 		return
 	}
 	if locked {
-		mu := sync.Mutex{}
-		rateKeys := make([]domain.RateKey, 0, len(r.currencyPairs))
-		wg := sync.WaitGroup{}
-		for _, pair := range r.currencyPairs {
-			from, to := pair[0], pair[1]
-			wg.Add(1)
-			go func(from, to string) {
-				defer wg.Done()
-				defer func() {
-					if r := recover(); r != nil {
-						log.Printf("Recovered from panic while refreshing rate for %s to %s: %v", from, to, r)
-					}
-				}()
-				rate, err := r.fetcher.FetchRate(ctx, from, to, today)
-				if err != nil {
-					log.Printf("Failed to fetch rate for %s to %s: %v", from, to, err)
-					return
-				}
-				mu.Lock()
-				defer mu.Unlock()
-				rateKeys = append(rateKeys, domain.RateKey{
-					RateKeyRequest: domain.RateKeyRequest{
-						From: from,
-						To:   to,
-						Date: today,
-					},
-					Rate: rate,
-				})
-			}(from, to)
+		mode = metrics.RefreshModeLocked
+		// keep the lease alive while refreshing, cancelling the refresh if it is lost
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		heartbeat := startHeartbeat(ctx, r.logger, r.locker, lease, r.lockTTL, r.lockTTL/3, cancel)
+		defer func() {
+			cancel()
+			if err := r.locker.Release(context.Background(), heartbeat.lease()); err != nil {
+				r.logger.ErrorContext(ctx, "failed to release lock", "error", err)
+			}
+		}()
+
+		// rates another instance stored within the last interval are only synced into the cache
+		fresh, stale := r.splitFresh(ctx, currencyPairs, today)
+		if len(fresh) > 0 {
+			if err := r.repo.BatchUpdateCache(ctx, fresh); err != nil {
+				r.logger.ErrorContext(ctx, "failed to update cache in batch", "rates", len(fresh), "error", err)
+			}
+		}
+		span.SetAttributes(attribute.Int("fresh", len(fresh)))
+		if len(stale) == 0 {
+			r.logger.InfoContext(ctx, "stored rates are fresh, skipping provider fetches", "pairs", len(currencyPairs))
+			r.lastSuccess.Store(time.Now().UnixNano())
+			return
 		}
-		wg.Wait()
+
+		rateKeys := r.fetchPairs(ctx, stale, today)
 		if len(rateKeys) > 0 {
-			err := r.repo.BatchUpdateDB(ctx, rateKeys)
+			fence := lease.FencingToken
+			err := r.repo.BatchUpdateDB(ctx, rateKeys, &fence)
 			if err != nil {
-				log.Printf("Failed to update rates in batch: %v for keys %v", err, rateKeys)
+				// a partial failure error lists exactly which rates were not persisted
+				r.logger.ErrorContext(ctx, "failed to update rates in batch", "error", err)
+				span.RecordError(err)
+			} else {
+				r.logger.InfoContext(ctx, "rate refresher stored rates", "rates", len(rateKeys), "pairs", len(stale))
+			}
+			span.SetAttributes(attribute.Int("fetched", len(rateKeys)))
+			markRefreshed(rateKeys, err)
+			if stored := storedRates(rateKeys, err); len(stored) > 0 {
+				if err := r.repo.BatchUpdateCache(ctx, stored); err != nil {
+					r.logger.ErrorContext(ctx, "failed to update cache in batch", "rates", len(stored), "error", err)
+				}
+				r.lastSuccess.Store(time.Now().UnixNano())
 			}
 		}
 		return
 	}
 
-	req := make([]domain.RateKeyRequest, len(r.currencyPairs))
-	for i, pair := range r.currencyPairs {
+	req := make([]domain.RateKeyRequest, len(currencyPairs))
+	for i, pair := range currencyPairs {
 		req[i] = domain.RateKeyRequest{
 			From: pair[0],
 			To:   pair[1],
@@ -104,16 +173,138 @@ func (r *RateRefresher) Run() {
 	}
 	rateKeys, err := r.repo.BatchGetFromDB(ctx, req)
 	if err != nil {
-		log.Printf("Failed to get rates from DB: %v for keys %v", err, req)
-		return
+		var partial *domain.BatchPartialFailureError
+		if !errors.As(err, &partial) {
+			r.logger.ErrorContext(ctx, "failed to get rates from db", "keys", len(req), "error", err)
+			return
+		}
+		// keep the rates that were read
+		r.logger.WarnContext(ctx, "failed to get some rates from db", "error", err)
 	}
 	if len(rateKeys) == 0 {
-		log.Printf("No rates found in DB for pairs: %v", r.currencyPairs)
+		r.logger.WarnContext(ctx, "no rates found in db", "pairs", len(currencyPairs))
 		return
 	}
 	err = r.repo.BatchUpdateCache(ctx, rateKeys)
 	if err != nil {
-		log.Printf("Failed to update cache in batch: %v for keys %v", err, rateKeys)
+		r.logger.ErrorContext(ctx, "failed to update cache in batch", "rates", len(rateKeys), "error", err)
+		return
+	}
+	r.lastSuccess.Store(time.Now().UnixNano())
+	r.logger.InfoContext(ctx, "rate refresher synced cache", "rates", len(rateKeys))
+}
+
+// splitFresh reads today's stored rates of the pairs, returning those stored within the last interval and
+// the pairs to fetch again. Pairs that could not be read are fetched again.
+func (r *RateRefresher) splitFresh(ctx context.Context, pairs [][2]string, today string) ([]domain.RateKey, [][2]string) {
+	req := make([]domain.RateKeyRequest, len(pairs))
+	for i, pair := range pairs {
+		req[i] = domain.RateKeyRequest{From: pair[0], To: pair[1], Date: today}
+	}
+	stored, err := r.repo.BatchGetFromDB(ctx, req)
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to read stored rates, fetching them again", "keys", len(req), "error", err)
+	}
+
+	fresh := make([]domain.RateKey, 0, len(stored))
+	isFresh := make(map[[2]string]bool, len(stored))
+	for _, rate := range stored {
+		if !rate.OlderThan(r.frequency) {
+			fresh = append(fresh, rate)
+			isFresh[[2]string{rate.From, rate.To}] = true
+		}
+	}
+	stale := make([][2]string, 0, len(pairs)-len(fresh))
+	for _, pair := range pairs {
+		if !isFresh[pair] {
+			stale = append(stale, pair)
+		}
+	}
+	return fresh, stale
+}
+
+// fetchPairs fetches today's rate of every pair from the providers, at most r.concurrency at once
+func (r *RateRefresher) fetchPairs(ctx context.Context, pairs [][2]string, today string) []domain.RateKey {
+	mu := sync.Mutex{}
+	rateKeys := make([]domain.RateKey, 0, len(pairs))
+	wg := sync.WaitGroup{}
+	sem := make(chan struct{}, r.concurrency)
+	for _, pair := range pairs {
+		from, to := pair[0], pair[1]
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(from, to string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer func() {
+				if rec := recover(); rec != nil {
+					r.logger.ErrorContext(ctx, "recovered from panic while refreshing rate", "from", from, "to", to, "panic", rec)
+				}
+			}()
+			fetched, err := r.fetcher.FetchRateWithSource(ctx, from, to, today)
+			if err != nil {
+				r.logger.WarnContext(ctx, "failed to fetch rate", "from", from, "to", to, "error", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			rateKeys = append(rateKeys, domain.RateKey{
+				RateKeyRequest: domain.RateKeyRequest{
+					From: from,
+					To:   to,
+					Date: today,
+				},
+				Rate: fetched.Rate,
+				Provenance: domain.Provenance{
+					Provider:    fetched.Provider,
+					FetchedAt:   time.Now().UTC(),
+					RefreshMode: domain.RefreshModeScheduled,
+				},
+			})
+		}(from, to)
+	}
+	wg.Wait()
+	return rateKeys
+}
+
+// storedRates returns the rates of a batch write that were persisted, none when it failed entirely
+func storedRates(rates []domain.RateKey, err error) []domain.RateKey {
+	if err == nil {
+		return rates
+	}
+	var partial *domain.BatchPartialFailureError
+	if !errors.As(err, &partial) {
+		return nil
+	}
+	failed := make(map[domain.RateKeyRequest]bool, len(partial.FailedWrites))
+	for _, rate := range partial.FailedWrites {
+		failed[rate.RateKeyRequest] = true
+	}
+	stored := make([]domain.RateKey, 0, len(rates))
+	for _, rate := range rates {
+		if !failed[rate.RateKeyRequest] {
+			stored = append(stored, rate)
+		}
+	}
+	return stored
+}
+
+// markRefreshed records the refresh time of the stored rates, skipping those reported by a partial failure
+func markRefreshed(rates []domain.RateKey, err error) {
+	failed := make(map[domain.RateKeyRequest]bool)
+	if err != nil {
+		var partial *domain.BatchPartialFailureError
+		if !errors.As(err, &partial) {
+			return
+		}
+		for _, rate := range partial.FailedWrites {
+			failed[rate.RateKeyRequest] = true
+		}
+	}
+	now := float64(time.Now().Unix())
+	for _, rate := range rates {
+		if !failed[rate.RateKeyRequest] {
+			metrics.LastRefresh.WithLabelValues(rate.From, rate.To).Set(now)
+		}
 	}
-	log.Printf("Rate refresher completed successfully for pairs: %v at time %v", r.currencyPairs, time.Now().Format("2006-01-02 15:04:05"))
 }
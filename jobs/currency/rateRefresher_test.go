@@ -0,0 +1,231 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	infra "github.com/ItsDee25/exchange-rate-service/infra/ratefetcher"
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+	repository "github.com/ItsDee25/exchange-rate-service/internal/repository/currency"
+	"github.com/ItsDee25/exchange-rate-service/mocks"
+)
+
+// stubFetcher serves a fixed rate for every pair after delay, tracking the fetches in flight
+type stubFetcher struct {
+	delay time.Duration
+
+	mu          sync.Mutex
+	fetches     int
+	inFlight    int
+	maxInFlight int
+}
+
+func (f *stubFetcher) FetchRate(ctx context.Context, from, to, date string) (float64, error) {
+	fetched, err := f.FetchRateWithSource(ctx, from, to, date)
+	return fetched.Rate, err
+}
+
+func (f *stubFetcher) FetchRateWithSource(ctx context.Context, from, to, date string) (domain.FetchedRate, error) {
+	f.mu.Lock()
+	f.fetches++
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	select {
+	case <-time.After(f.delay):
+		return domain.FetchedRate{Rate: 1.5, Provider: "stub"}, nil
+	case <-ctx.Done():
+		return domain.FetchedRate{}, ctx.Err()
+	}
+}
+
+func (f *stubFetcher) Budget() time.Duration {
+	return time.Second
+}
+
+// stubRegistry enables every ordered pair of currencies
+type stubRegistry struct {
+	currencies []string
+}
+
+func (r stubRegistry) Lookup(ctx context.Context, code string) (domain.Currency, bool) {
+	return domain.Currency{}, false
+}
+func (r stubRegistry) IsEnabled(ctx context.Context, code string) bool { return true }
+func (r stubRegistry) List(ctx context.Context) []domain.Currency      { return nil }
+func (r stubRegistry) EnabledPairs(ctx context.Context) [][2]string {
+	pairs := make([][2]string, 0)
+	for _, from := range r.currencies {
+		for _, to := range r.currencies {
+			if from != to {
+				pairs = append(pairs, [2]string{from, to})
+			}
+		}
+	}
+	return pairs
+}
+
+func newTestRefresher(fake *mocks.DynamoFake, fetcher *stubFetcher, registry stubRegistry, concurrency int) *RateRefresher {
+	repo := repository.NewDynamoRepository(fake, fetcher, repository.NewLRURateCache())
+	return NewRateRefresher(repo, fetcher, infra.NewDynamoLocker(fake), registry, time.Hour, time.Minute, concurrency)
+}
+
+func TestRateRefresherBoundsConcurrentFetches(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	fetcher := &stubFetcher{delay: 10 * time.Millisecond}
+	registry := stubRegistry{currencies: []string{"USD", "EUR", "GBP", "INR", "JPY"}}
+	refresher := newTestRefresher(fake, fetcher, registry, 3)
+
+	refresher.Run(context.Background())
+
+	pairs := len(registry.EnabledPairs(context.Background()))
+	if fetcher.fetches != pairs {
+		t.Errorf("fetches = %d, want one per pair (%d)", fetcher.fetches, pairs)
+	}
+	if fetcher.maxInFlight > 3 {
+		t.Errorf("%d fetches in flight at once, want at most 3", fetcher.maxInFlight)
+	}
+}
+
+func TestRateRefresherRunStoresRates(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	fetcher := &stubFetcher{}
+	registry := stubRegistry{currencies: []string{"USD", "EUR", "INR"}}
+	refresher := newTestRefresher(fake, fetcher, registry, 2)
+
+	refresher.Run(context.Background())
+
+	today := time.Now().Format("2006-01-02")
+	repo := repository.NewDynamoRepository(fake, fetcher, repository.NewLRURateCache())
+	for _, pair := range registry.EnabledPairs(context.Background()) {
+		rate, err := repo.GetDataFromDB(context.Background(), pair[0], pair[1], today)
+		if err != nil {
+			t.Errorf("%s/%s not stored: %v", pair[0], pair[1], err)
+			continue
+		}
+		if rate.Rate != 1.5 || rate.Provider != "stub" || rate.RefreshMode != domain.RefreshModeScheduled {
+			t.Errorf("%s/%s = %+v, want 1.5 from stub, scheduled", pair[0], pair[1], rate)
+		}
+	}
+	// the rates are written fenced by the lease, which is released once done
+	if calls := fake.Calls("TransactWriteItems"); calls != 1 {
+		t.Errorf("TransactWriteItems calls = %d, want 1", calls)
+	}
+	if _, locked, err := infra.NewDynamoLocker(fake).AcquireLock(context.Background(), lockId, time.Minute); err != nil || !locked {
+		t.Errorf("lock not released: locked = %v, err = %v", locked, err)
+	}
+	if refresher.LastSuccess().IsZero() {
+		t.Error("LastSuccess not set after storing rates")
+	}
+}
+
+func TestRateRefresherRunCachesStoredRates(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	fetcher := &stubFetcher{}
+	registry := stubRegistry{currencies: []string{"USD", "EUR", "INR"}}
+	repo := repository.NewDynamoRepository(fake, fetcher, repository.NewLRURateCache())
+	refresher := NewRateRefresher(repo, fetcher, infra.NewDynamoLocker(fake), registry, time.Hour, time.Minute, 2)
+
+	refresher.Run(context.Background())
+
+	today := time.Now().Format("2006-01-02")
+	for _, pair := range registry.EnabledPairs(context.Background()) {
+		rate, err := repo.GetRate(context.Background(), pair[0], pair[1], today, 0)
+		if err != nil || rate.Source != domain.RateSourceCache {
+			t.Errorf("%s/%s = %+v, %v, want the stored rate cached by the locked instance", pair[0], pair[1], rate, err)
+		}
+	}
+}
+
+func TestRateRefresherRunSkipsFreshRates(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	registry := stubRegistry{currencies: []string{"USD", "EUR", "INR"}}
+	pairs := len(registry.EnabledPairs(context.Background()))
+	newTestRefresher(fake, &stubFetcher{}, registry, 2).Run(context.Background())
+
+	tests := []struct {
+		name      string
+		frequency time.Duration
+		fetches   int
+	}{
+		{"stored within the interval", time.Hour, 0},
+		{"stored before the interval", time.Nanosecond, pairs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &stubFetcher{}
+			repo := repository.NewDynamoRepository(fake, fetcher, repository.NewLRURateCache())
+			refresher := NewRateRefresher(repo, fetcher, infra.NewDynamoLocker(fake), registry, tt.frequency, time.Minute, 2)
+
+			refresher.Run(context.Background())
+
+			if fetcher.fetches != tt.fetches {
+				t.Errorf("fetches = %d, want %d", fetcher.fetches, tt.fetches)
+			}
+			today := time.Now().Format("2006-01-02")
+			for _, pair := range registry.EnabledPairs(context.Background()) {
+				rate, err := repo.GetRate(context.Background(), pair[0], pair[1], today, 0)
+				if err != nil || rate.Source != domain.RateSourceCache {
+					t.Errorf("%s/%s = %+v, %v, want it cached", pair[0], pair[1], rate, err)
+				}
+			}
+			if refresher.LastSuccess().IsZero() {
+				t.Error("LastSuccess not set")
+			}
+		})
+	}
+}
+
+func TestRateRefresherRunSyncsCacheWhenLocked(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	registry := stubRegistry{currencies: []string{"USD", "EUR"}}
+	// another instance refreshed the rates and still holds the lock
+	newTestRefresher(fake, &stubFetcher{}, registry, 2).Run(context.Background())
+	if _, locked, err := infra.NewDynamoLocker(fake).AcquireLock(context.Background(), lockId, time.Minute); err != nil || !locked {
+		t.Fatalf("AcquireLock: locked = %v, err = %v", locked, err)
+	}
+
+	fetcher := &stubFetcher{}
+	repo := repository.NewDynamoRepository(fake, fetcher, repository.NewLRURateCache())
+	refresher := NewRateRefresher(repo, fetcher, infra.NewDynamoLocker(fake), registry, time.Hour, time.Minute, 2)
+	refresher.Run(context.Background())
+
+	if fetcher.fetches != 0 {
+		t.Errorf("fetches = %d, an instance without the lock must not call the provider", fetcher.fetches)
+	}
+	today := time.Now().Format("2006-01-02")
+	for _, pair := range registry.EnabledPairs(context.Background()) {
+		rate, err := repo.GetRate(context.Background(), pair[0], pair[1], today, 0)
+		if err != nil || rate.Source != domain.RateSourceCache {
+			t.Errorf("%s/%s = %+v, %v, want it synced into the cache", pair[0], pair[1], rate, err)
+		}
+	}
+	if refresher.LastSuccess().IsZero() {
+		t.Error("LastSuccess not set after syncing the cache")
+	}
+}
+
+func TestRateRefresherRunLockError(t *testing.T) {
+	fake := mocks.NewDynamoFake()
+	fake.FailNext = errors.New("connection refused")
+	fetcher := &stubFetcher{}
+	refresher := newTestRefresher(fake, fetcher, stubRegistry{currencies: []string{"USD", "EUR"}}, 2)
+
+	refresher.Run(context.Background())
+
+	if fetcher.fetches != 0 || len(fake.Items()) != 0 {
+		t.Errorf("fetches = %d, items = %d, a run that failed to take the lock must do nothing", fetcher.fetches, len(fake.Items()))
+	}
+	if !refresher.LastSuccess().IsZero() {
+		t.Error("LastSuccess set by a failed run")
+	}
+}
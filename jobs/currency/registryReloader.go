@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
+)
+
+// registryReloader picks up currency registry changes made through the admin api of other instances
+type registryReloader struct {
+	registry  domain.ICurrencyRegistryRepository
+	frequency time.Duration
+	logger    *slog.Logger
+	done      chan struct{}
+}
+
+// NewRegistryReloader builds a reloader of the currency registry, running every frequency
+func NewRegistryReloader(registry domain.ICurrencyRegistryRepository, frequency time.Duration) *registryReloader {
+	return &registryReloader{
+		registry:  registry,
+		frequency: frequency,
+		logger:    slog.Default().With("component", "RegistryReloader"),
+		done:      make(chan struct{}),
+	}
+}
+
+func (r *registryReloader) WithLogger(logger *slog.Logger) *registryReloader {
+	r.logger = logger.With("component", "RegistryReloader")
+	return r
+}
+
+// Start reloads the registry every r.frequency until ctx is done
+func (r *registryReloader) Start(ctx context.Context) {
+	r.logger.Info("starting currency registry reloader job", "frequency", r.frequency.String())
+	ticker := time.NewTicker(r.frequency)
+	go func() {
+		defer close(r.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.Run(context.WithoutCancel(ctx))
+			case <-ctx.Done():
+				r.logger.Info("stopping currency registry reloader job")
+				return
+			}
+		}
+	}()
+}
+
+// Wait blocks until the job started by Start has stopped, or ctx is done
+func (r *registryReloader) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *registryReloader) Run(ctx context.Context) {
+	if err := r.registry.Reload(ctx); err != nil {
+		// keep serving the last loaded registry
+		r.logger.ErrorContext(ctx, "failed to reload currency registry", "error", err)
+	}
+}
@@ -0,0 +1,467 @@
+package mocks
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// This file evaluates the subset of DynamoDB condition, key condition and update expressions
+// used by the service: comparisons, BETWEEN, begins_with, attribute_exists/attribute_not_exists,
+// AND/OR/NOT with parentheses, and SET/REMOVE/ADD update clauses on top level attributes.
+
+type item = map[string]types.AttributeValue
+
+type exprTokenizer struct {
+	tokens []string
+	pos    int
+}
+
+func tokenize(expr string) ([]string, error) {
+	tokens := make([]string, 0)
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("(),+-", r):
+			tokens = append(tokens, string(r))
+			i++
+		case r == '=':
+			tokens = append(tokens, "=")
+			i++
+		case r == '<' || r == '>':
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')) {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+			} else {
+				tokens = append(tokens, string(r))
+				i++
+			}
+		case r == '#' || r == ':' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := i
+			i++
+			for i < len(runes) && (runes[i] == '_' || runes[i] == '.' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		default:
+			return nil, fmt.Errorf("unexpected character %q in expression %q", r, expr)
+		}
+	}
+	return tokens, nil
+}
+
+func (t *exprTokenizer) peek() string {
+	if t.pos >= len(t.tokens) {
+		return ""
+	}
+	return t.tokens[t.pos]
+}
+
+func (t *exprTokenizer) next() string {
+	tok := t.peek()
+	t.pos++
+	return tok
+}
+
+func (t *exprTokenizer) expect(tok string) error {
+	if got := t.next(); !strings.EqualFold(got, tok) {
+		return fmt.Errorf("expected %q, got %q", tok, got)
+	}
+	return nil
+}
+
+func (t *exprTokenizer) peekKeyword(kw string) bool {
+	return strings.EqualFold(t.peek(), kw)
+}
+
+// exprContext resolves #name and :value placeholders
+type exprContext struct {
+	names  map[string]string
+	values map[string]types.AttributeValue
+}
+
+func (c exprContext) attributeName(tok string) (string, error) {
+	if strings.HasPrefix(tok, "#") {
+		name, ok := c.names[tok]
+		if !ok {
+			return "", fmt.Errorf("undefined expression attribute name %s", tok)
+		}
+		return name, nil
+	}
+	return tok, nil
+}
+
+// operand is either a path into the item or a placeholder value
+type operand struct {
+	path  string
+	value types.AttributeValue
+}
+
+func (c exprContext) parseOperand(t *exprTokenizer) (operand, error) {
+	tok := t.next()
+	if tok == "" {
+		return operand{}, fmt.Errorf("unexpected end of expression")
+	}
+	if strings.HasPrefix(tok, ":") {
+		v, ok := c.values[tok]
+		if !ok {
+			return operand{}, fmt.Errorf("undefined expression attribute value %s", tok)
+		}
+		return operand{value: v}, nil
+	}
+	name, err := c.attributeName(tok)
+	if err != nil {
+		return operand{}, err
+	}
+	return operand{path: name}, nil
+}
+
+func (o operand) resolve(it item) (types.AttributeValue, bool) {
+	if o.value != nil {
+		return o.value, true
+	}
+	v, ok := it[o.path]
+	return v, ok
+}
+
+// evalCondition evaluates a condition or key condition expression against an item, nil items behave as absent.
+func evalCondition(expr string, ctx exprContext, it item) (bool, error) {
+	if expr == "" {
+		return true, nil
+	}
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return false, err
+	}
+	t := &exprTokenizer{tokens: tokens}
+	ok, err := ctx.parseOr(t, it)
+	if err != nil {
+		return false, fmt.Errorf("invalid expression %q: %w", expr, err)
+	}
+	if t.peek() != "" {
+		return false, fmt.Errorf("invalid expression %q: unexpected %q", expr, t.peek())
+	}
+	return ok, nil
+}
+
+func (c exprContext) parseOr(t *exprTokenizer, it item) (bool, error) {
+	result, err := c.parseAnd(t, it)
+	if err != nil {
+		return false, err
+	}
+	for t.peekKeyword("OR") {
+		t.next()
+		rhs, err := c.parseAnd(t, it)
+		if err != nil {
+			return false, err
+		}
+		result = result || rhs
+	}
+	return result, nil
+}
+
+func (c exprContext) parseAnd(t *exprTokenizer, it item) (bool, error) {
+	result, err := c.parseNot(t, it)
+	if err != nil {
+		return false, err
+	}
+	for t.peekKeyword("AND") {
+		t.next()
+		rhs, err := c.parseNot(t, it)
+		if err != nil {
+			return false, err
+		}
+		result = result && rhs
+	}
+	return result, nil
+}
+
+func (c exprContext) parseNot(t *exprTokenizer, it item) (bool, error) {
+	if t.peekKeyword("NOT") {
+		t.next()
+		v, err := c.parseNot(t, it)
+		return !v, err
+	}
+	return c.parsePrimary(t, it)
+}
+
+func (c exprContext) parsePrimary(t *exprTokenizer, it item) (bool, error) {
+	if t.peek() == "(" {
+		t.next()
+		v, err := c.parseOr(t, it)
+		if err != nil {
+			return false, err
+		}
+		return v, t.expect(")")
+	}
+
+	switch strings.ToLower(t.peek()) {
+	case "attribute_exists", "attribute_not_exists":
+		fn := strings.ToLower(t.next())
+		if err := t.expect("("); err != nil {
+			return false, err
+		}
+		name, err := c.attributeName(t.next())
+		if err != nil {
+			return false, err
+		}
+		if err := t.expect(")"); err != nil {
+			return false, err
+		}
+		_, exists := it[name]
+		return exists == (fn == "attribute_exists"), nil
+	case "begins_with":
+		t.next()
+		if err := t.expect("("); err != nil {
+			return false, err
+		}
+		lhs, err := c.parseOperand(t)
+		if err != nil {
+			return false, err
+		}
+		if err := t.expect(","); err != nil {
+			return false, err
+		}
+		rhs, err := c.parseOperand(t)
+		if err != nil {
+			return false, err
+		}
+		if err := t.expect(")"); err != nil {
+			return false, err
+		}
+		l, lok := lhs.resolve(it)
+		r, rok := rhs.resolve(it)
+		ls, lIsS := l.(*types.AttributeValueMemberS)
+		rs, rIsS := r.(*types.AttributeValueMemberS)
+		return lok && rok && lIsS && rIsS && strings.HasPrefix(ls.Value, rs.Value), nil
+	}
+
+	lhs, err := c.parseOperand(t)
+	if err != nil {
+		return false, err
+	}
+	op := t.next()
+	if strings.EqualFold(op, "BETWEEN") {
+		low, err := c.parseOperand(t)
+		if err != nil {
+			return false, err
+		}
+		if err := t.expect("AND"); err != nil {
+			return false, err
+		}
+		high, err := c.parseOperand(t)
+		if err != nil {
+			return false, err
+		}
+		v, ok := lhs.resolve(it)
+		lo, _ := low.resolve(it)
+		hi, _ := high.resolve(it)
+		if !ok {
+			return false, nil
+		}
+		geLow, okLow := compareValues(v, lo)
+		leHigh, okHigh := compareValues(v, hi)
+		return okLow && okHigh && geLow >= 0 && leHigh <= 0, nil
+	}
+
+	rhs, err := c.parseOperand(t)
+	if err != nil {
+		return false, err
+	}
+	l, lok := lhs.resolve(it)
+	r, rok := rhs.resolve(it)
+	if !lok || !rok {
+		return op == "<>" && lok != rok, nil
+	}
+	cmp, comparable := compareValues(l, r)
+	switch op {
+	case "=":
+		return comparable && cmp == 0, nil
+	case "<>":
+		return !comparable || cmp != 0, nil
+	case "<":
+		return comparable && cmp < 0, nil
+	case "<=":
+		return comparable && cmp <= 0, nil
+	case ">":
+		return comparable && cmp > 0, nil
+	case ">=":
+		return comparable && cmp >= 0, nil
+	}
+	return false, fmt.Errorf("unsupported operator %q", op)
+}
+
+// compareValues orders two scalar attribute values of the same type
+func compareValues(a, b types.AttributeValue) (int, bool) {
+	switch av := a.(type) {
+	case *types.AttributeValueMemberS:
+		bv, ok := b.(*types.AttributeValueMemberS)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av.Value, bv.Value), true
+	case *types.AttributeValueMemberN:
+		bv, ok := b.(*types.AttributeValueMemberN)
+		if !ok {
+			return 0, false
+		}
+		x, okx := new(big.Float).SetString(av.Value)
+		y, oky := new(big.Float).SetString(bv.Value)
+		if !okx || !oky {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	case *types.AttributeValueMemberBOOL:
+		bv, ok := b.(*types.AttributeValueMemberBOOL)
+		if !ok || av.Value != bv.Value {
+			return 1, ok
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// applyUpdate applies SET, REMOVE and ADD clauses to a copy of the item and returns it.
+func applyUpdate(expr string, ctx exprContext, it item) (item, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	t := &exprTokenizer{tokens: tokens}
+	updated := copyItem(it)
+
+	for t.peek() != "" {
+		clause := strings.ToUpper(t.next())
+		for {
+			name, err := ctx.attributeName(t.next())
+			if err != nil {
+				return nil, err
+			}
+			switch clause {
+			case "SET":
+				if err := t.expect("="); err != nil {
+					return nil, err
+				}
+				v, err := ctx.parseSetValue(t, it)
+				if err != nil {
+					return nil, err
+				}
+				updated[name] = v
+			case "REMOVE":
+				delete(updated, name)
+			case "ADD":
+				delta, err := ctx.parseOperand(t)
+				if err != nil {
+					return nil, err
+				}
+				dv, _ := delta.resolve(it)
+				current, ok := updated[name]
+				if !ok {
+					updated[name] = dv
+					break
+				}
+				sum, err := addNumbers(current, dv, 1)
+				if err != nil {
+					return nil, err
+				}
+				updated[name] = sum
+			default:
+				return nil, fmt.Errorf("unsupported update clause %q in %q", clause, expr)
+			}
+			if t.peek() != "," {
+				break
+			}
+			t.next()
+		}
+	}
+	return updated, nil
+}
+
+// parseSetValue parses operand, operand + operand, operand - operand or if_not_exists(path, operand)
+func (c exprContext) parseSetValue(t *exprTokenizer, it item) (types.AttributeValue, error) {
+	var value types.AttributeValue
+	if strings.EqualFold(t.peek(), "if_not_exists") {
+		t.next()
+		if err := t.expect("("); err != nil {
+			return nil, err
+		}
+		path, err := c.parseOperand(t)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.expect(","); err != nil {
+			return nil, err
+		}
+		fallback, err := c.parseOperand(t)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.expect(")"); err != nil {
+			return nil, err
+		}
+		if v, ok := path.resolve(it); ok {
+			value = v
+		} else {
+			value, _ = fallback.resolve(it)
+		}
+	} else {
+		o, err := c.parseOperand(t)
+		if err != nil {
+			return nil, err
+		}
+		v, ok := o.resolve(it)
+		if !ok {
+			return nil, fmt.Errorf("attribute %s does not exist", o.path)
+		}
+		value = v
+	}
+
+	if op := t.peek(); op == "+" || op == "-" {
+		t.next()
+		o, err := c.parseOperand(t)
+		if err != nil {
+			return nil, err
+		}
+		rhs, ok := o.resolve(it)
+		if !ok {
+			return nil, fmt.Errorf("attribute %s does not exist", o.path)
+		}
+		sign := 1
+		if op == "-" {
+			sign = -1
+		}
+		return addNumbers(value, rhs, sign)
+	}
+	return value, nil
+}
+
+func addNumbers(a, b types.AttributeValue, sign int) (types.AttributeValue, error) {
+	an, aok := a.(*types.AttributeValueMemberN)
+	bn, bok := b.(*types.AttributeValueMemberN)
+	if !aok || !bok {
+		return nil, fmt.Errorf("arithmetic on non-number attributes")
+	}
+	x, okx := new(big.Float).SetPrec(128).SetString(an.Value)
+	y, oky := new(big.Float).SetPrec(128).SetString(bn.Value)
+	if !okx || !oky {
+		return nil, fmt.Errorf("invalid numbers %q, %q", an.Value, bn.Value)
+	}
+	if sign < 0 {
+		y.Neg(y)
+	}
+	return &types.AttributeValueMemberN{Value: x.Add(x, y).Text('f', -1)}, nil
+}
+
+func copyItem(it item) item {
+	copied := make(item, len(it))
+	for k, v := range it {
+		copied[k] = v
+	}
+	return copied
+}
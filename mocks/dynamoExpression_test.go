@@ -0,0 +1,172 @@
+package mocks
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func s(v string) types.AttributeValue { return &types.AttributeValueMemberS{Value: v} }
+func n(v string) types.AttributeValue { return &types.AttributeValueMemberN{Value: v} }
+
+func TestEvalCondition(t *testing.T) {
+	it := item{
+		"pk":      s("USD#INR"),
+		"sk":      s("2024-06-01"),
+		"owner":   s("a"),
+		"fence":   n("3"),
+		"expires": n("100"),
+	}
+	ctx := exprContext{
+		names: map[string]string{"#pk": "pk", "#sk": "sk", "#owner": "owner", "#fence": "fence", "#expires": "expires"},
+		values: map[string]types.AttributeValue{
+			":pk":    s("USD#INR"),
+			":a":     s("a"),
+			":b":     s("b"),
+			":three": n("3"),
+			":now":   n("99"),
+			":later": n("101"),
+			":start": s("2024-05-01"),
+			":end":   s("2024-06-30"),
+			":month": s("2024-06"),
+		},
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "#pk = :pk", want: true},
+		{expr: "#owner <> :a", want: false},
+		{expr: "#fence = :three AND #owner = :a", want: true},
+		{expr: "#owner = :b OR #fence = :three", want: true},
+		{expr: "NOT #owner = :a", want: false},
+		{expr: "#expires < :now OR #owner = :b", want: false},
+		{expr: "#expires >= :now AND #expires < :later", want: true},
+		// numbers compare numerically, not as strings
+		{expr: "#fence < :later", want: true},
+		{expr: "#sk BETWEEN :start AND :end", want: true},
+		{expr: "begins_with(#sk, :month)", want: true},
+		{expr: "attribute_exists(#owner) AND attribute_not_exists(missing)", want: true},
+		{expr: "attribute_not_exists(#pk) OR (#expires < :now AND #owner = :b)", want: false},
+		{expr: "(attribute_not_exists(#pk) OR #expires < :later) AND #owner = :a", want: true},
+	}
+	for _, tt := range tests {
+		got, err := evalCondition(tt.expr, ctx, it)
+		if err != nil {
+			t.Errorf("evalCondition(%q): %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("evalCondition(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEvalConditionOnAbsentItem(t *testing.T) {
+	ctx := exprContext{
+		names:  map[string]string{"#pk": "pk", "#owner": "owner"},
+		values: map[string]types.AttributeValue{":a": s("a")},
+	}
+	if ok, err := evalCondition("attribute_not_exists(#pk)", ctx, nil); err != nil || !ok {
+		t.Errorf("attribute_not_exists on an absent item = %v, %v, want true", ok, err)
+	}
+	if ok, err := evalCondition("#owner = :a", ctx, nil); err != nil || ok {
+		t.Errorf("comparison on an absent item = %v, %v, want false", ok, err)
+	}
+}
+
+func TestEvalConditionErrors(t *testing.T) {
+	ctx := exprContext{names: map[string]string{}, values: map[string]types.AttributeValue{}}
+	for _, expr := range []string{
+		"#undefined = :a",
+		"owner = :undefined",
+		"owner =",
+		"(owner = owner",
+	} {
+		if _, err := evalCondition(expr, ctx, item{"owner": s("a")}); err == nil {
+			t.Errorf("evalCondition(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	it := item{
+		"pk":      s("rate_refresher_lock"),
+		"sk":      s("LOCK"),
+		"owner":   s("a"),
+		"fence":   n("1"),
+		"expires": n("100"),
+		"count":   n("5"),
+	}
+	ctx := exprContext{
+		names: map[string]string{"#owner": "owner", "#fence": "fence", "#expires": "expires"},
+		values: map[string]types.AttributeValue{
+			":owner":   s("b"),
+			":expires": n("200"),
+			":one":     n("1"),
+			":two":     n("2"),
+			":zero":    n("0"),
+		},
+	}
+
+	updated, err := applyUpdate("SET #owner = :owner, #expires = :expires, count = count - :two, created = if_not_exists(created, :zero) ADD #fence :one, added :two REMOVE pk_extra", ctx, it)
+	if err != nil {
+		t.Fatalf("applyUpdate: %v", err)
+	}
+	want := map[string]string{
+		"owner":   "b",
+		"expires": "200",
+		"count":   "3",
+		"created": "0",
+		"fence":   "2",
+		"added":   "2",
+	}
+	for name, value := range want {
+		if got := attributeString(updated[name]); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	// the item passed in is left untouched
+	if attributeString(it["owner"]) != "a" || attributeString(it["fence"]) != "1" {
+		t.Errorf("applyUpdate modified its input: %v", it)
+	}
+
+	removed, err := applyUpdate("SET #expires = :zero REMOVE #owner", ctx, it)
+	if err != nil {
+		t.Fatalf("applyUpdate: %v", err)
+	}
+	if _, ok := removed["owner"]; ok {
+		t.Error("owner was not removed")
+	}
+	if attributeString(removed["expires"]) != "0" {
+		t.Errorf("expires = %q, want 0", attributeString(removed["expires"]))
+	}
+}
+
+func TestApplyUpdateErrors(t *testing.T) {
+	ctx := exprContext{
+		names:  map[string]string{},
+		values: map[string]types.AttributeValue{":s": s("x"), ":one": n("1")},
+	}
+	for _, expr := range []string{
+		"SET missing = missing + :one",
+		"SET name = :s + :one",
+		"DELETE name",
+		"SET name = :undefined",
+	} {
+		if _, err := applyUpdate(expr, ctx, item{"name": s("a")}); err == nil {
+			t.Errorf("applyUpdate(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func attributeString(v types.AttributeValue) string {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return v.Value
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,546 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	pkg "github.com/ItsDee25/exchange-rate-service/pkg/awsclient"
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	fakeBatchGetLimit     = 100
+	fakeBatchWriteLimit   = 25
+	fakeTransactItemLimit = 100
+)
+
+var _ pkg.DynamoAPI = (*DynamoFake)(nil)
+
+// DynamoFake is an in-process stand-in for a DynamoDB table keyed by pk (hash) and sk (range),
+// implementing the pkg/awsclient.DynamoAPI subset the repositories and the locker use.
+// Items whose ttl attribute is in the past are treated as deleted, as DynamoDB TTL would.
+type DynamoFake struct {
+	mu        sync.Mutex
+	tableName string
+	items     map[string]map[string]item
+
+	// Now is the clock used for TTL expiry
+	Now func() time.Time
+	// QueryPageSize caps the items returned per Query page to exercise pagination, 0 means unlimited
+	QueryPageSize int
+	// UnprocessedBatchCalls makes the next n batch get/write calls process only the first half of their
+	// keys and return the rest as UnprocessedKeys/UnprocessedItems, like DynamoDB does when throttled
+	UnprocessedBatchCalls int
+	// FailNext, when set, is returned by the next call instead of executing it
+	FailNext error
+
+	calls map[string]int
+}
+
+func NewDynamoFake() *DynamoFake {
+	return &DynamoFake{
+		tableName: constants.TableName,
+		items:     map[string]map[string]item{},
+		Now:       time.Now,
+		calls:     map[string]int{},
+	}
+}
+
+// Calls returns how many times the given operation (e.g. "BatchWriteItem") was called
+func (f *DynamoFake) Calls(op string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[op]
+}
+
+// Items returns a copy of every live item, ordered by pk then sk
+func (f *DynamoFake) Items() []map[string]types.AttributeValue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sortedItems(false)
+}
+
+// Seed stores items directly, bypassing conditions
+func (f *DynamoFake) Seed(items ...map[string]types.AttributeValue) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, it := range items {
+		if err := f.put(it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpireItem moves the expires_at lease of the stored item a minute into the past of the fake clock,
+// as if the lock holder stopped renewing it
+func (f *DynamoFake) ExpireItem(pk, sk string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	it, ok := f.items[pk][sk]
+	if !ok || f.expired(it) {
+		return fmt.Errorf("item %s/%s not stored", pk, sk)
+	}
+	it[constants.ExpiresAt] = &types.AttributeValueMemberN{Value: strconv.FormatInt(f.Now().Add(-time.Minute).Unix(), 10)}
+	return nil
+}
+
+func (f *DynamoFake) begin(op string, table *string) error {
+	f.calls[op]++
+	if f.FailNext != nil {
+		err := f.FailNext
+		f.FailNext = nil
+		return err
+	}
+	if table != nil && aws.ToString(table) != f.tableName {
+		return &types.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("table %s not found", aws.ToString(table)))}
+	}
+	return nil
+}
+
+func validationError(format string, args ...any) error {
+	return fmt.Errorf("ValidationException: "+format, args...)
+}
+
+func keyOf(it item) (string, string, error) {
+	pk, ok := it[constants.PartitionKey].(*types.AttributeValueMemberS)
+	if !ok || pk.Value == "" {
+		return "", "", validationError("missing key attribute %s", constants.PartitionKey)
+	}
+	sk, ok := it[constants.SortKey].(*types.AttributeValueMemberS)
+	if !ok || sk.Value == "" {
+		return "", "", validationError("missing key attribute %s", constants.SortKey)
+	}
+	return pk.Value, sk.Value, nil
+}
+
+func (f *DynamoFake) expired(it item) bool {
+	ttl, ok := it[constants.TTL].(*types.AttributeValueMemberN)
+	if !ok {
+		return false
+	}
+	epoch, err := strconv.ParseInt(ttl.Value, 10, 64)
+	return err == nil && epoch < f.Now().Unix()
+}
+
+// get returns the live item for the key, or nil
+func (f *DynamoFake) get(key item) (item, error) {
+	pk, sk, err := keyOf(key)
+	if err != nil {
+		return nil, err
+	}
+	it, ok := f.items[pk][sk]
+	if !ok || f.expired(it) {
+		return nil, nil
+	}
+	return copyItem(it), nil
+}
+
+func (f *DynamoFake) put(it item) error {
+	pk, sk, err := keyOf(it)
+	if err != nil {
+		return err
+	}
+	if f.items[pk] == nil {
+		f.items[pk] = map[string]item{}
+	}
+	f.items[pk][sk] = copyItem(it)
+	return nil
+}
+
+func (f *DynamoFake) delete(key item) error {
+	pk, sk, err := keyOf(key)
+	if err != nil {
+		return err
+	}
+	delete(f.items[pk], sk)
+	return nil
+}
+
+func (f *DynamoFake) sortedItems(includeExpired bool) []item {
+	pks := make([]string, 0, len(f.items))
+	for pk := range f.items {
+		pks = append(pks, pk)
+	}
+	sort.Strings(pks)
+	result := make([]item, 0)
+	for _, pk := range pks {
+		sks := make([]string, 0, len(f.items[pk]))
+		for sk := range f.items[pk] {
+			sks = append(sks, sk)
+		}
+		sort.Strings(sks)
+		for _, sk := range sks {
+			it := f.items[pk][sk]
+			if !includeExpired && f.expired(it) {
+				continue
+			}
+			result = append(result, copyItem(it))
+		}
+	}
+	return result
+}
+
+func conditionFailed() error {
+	return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+}
+
+func (f *DynamoFake) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("GetItem", params.TableName); err != nil {
+		return nil, err
+	}
+	it, err := f.get(params.Key)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.GetItemOutput{Item: it}, nil
+}
+
+func (f *DynamoFake) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("PutItem", params.TableName); err != nil {
+		return nil, err
+	}
+	existing, err := f.get(params.Item)
+	if err != nil {
+		return nil, err
+	}
+	exprCtx := exprContext{names: params.ExpressionAttributeNames, values: params.ExpressionAttributeValues}
+	ok, err := evalCondition(aws.ToString(params.ConditionExpression), exprCtx, existing)
+	if err != nil {
+		return nil, validationError("%v", err)
+	}
+	if !ok {
+		return nil, conditionFailed()
+	}
+	if err := f.put(params.Item); err != nil {
+		return nil, err
+	}
+	out := &dynamodb.PutItemOutput{}
+	if params.ReturnValues == types.ReturnValueAllOld {
+		out.Attributes = existing
+	}
+	return out, nil
+}
+
+func (f *DynamoFake) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("UpdateItem", params.TableName); err != nil {
+		return nil, err
+	}
+	updated, existing, err := f.update(params.Key, params.UpdateExpression, params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.put(updated); err != nil {
+		return nil, err
+	}
+
+	out := &dynamodb.UpdateItemOutput{}
+	switch params.ReturnValues {
+	case types.ReturnValueAllNew:
+		out.Attributes = copyItem(updated)
+	case types.ReturnValueAllOld:
+		out.Attributes = existing
+	}
+	return out, nil
+}
+
+// update checks the condition against the current item and returns the updated item along with the old one
+func (f *DynamoFake) update(key item, updateExpr, condExpr *string, names map[string]string, values map[string]types.AttributeValue) (item, item, error) {
+	existing, err := f.get(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	exprCtx := exprContext{names: names, values: values}
+	ok, err := evalCondition(aws.ToString(condExpr), exprCtx, existing)
+	if err != nil {
+		return nil, nil, validationError("%v", err)
+	}
+	if !ok {
+		return nil, nil, conditionFailed()
+	}
+
+	base := existing
+	if base == nil {
+		base = copyItem(key)
+	}
+	updated, err := applyUpdate(aws.ToString(updateExpr), exprCtx, base)
+	if err != nil {
+		return nil, nil, validationError("%v", err)
+	}
+	// key attributes cannot be updated
+	for k, v := range key {
+		updated[k] = v
+	}
+	return updated, existing, nil
+}
+
+func (f *DynamoFake) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("BatchGetItem", nil); err != nil {
+		return nil, err
+	}
+
+	out := &dynamodb.BatchGetItemOutput{
+		Responses:       map[string][]map[string]types.AttributeValue{},
+		UnprocessedKeys: map[string]types.KeysAndAttributes{},
+	}
+	total := 0
+	for table, req := range params.RequestItems {
+		if table != f.tableName {
+			return nil, &types.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("table %s not found", table))}
+		}
+		total += len(req.Keys)
+		seen := map[string]struct{}{}
+		for _, key := range req.Keys {
+			pk, sk, err := keyOf(key)
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := seen[pk+"\x00"+sk]; dup {
+				return nil, validationError("provided list of item keys contains duplicates")
+			}
+			seen[pk+"\x00"+sk] = struct{}{}
+		}
+	}
+	if total > fakeBatchGetLimit {
+		return nil, validationError("too many items requested for the BatchGetItem call: %d", total)
+	}
+
+	for table, req := range params.RequestItems {
+		keys := req.Keys
+		if f.UnprocessedBatchCalls > 0 && len(keys) > 1 {
+			out.UnprocessedKeys[table] = types.KeysAndAttributes{Keys: keys[len(keys)/2:]}
+			keys = keys[:len(keys)/2]
+		}
+		for _, key := range keys {
+			it, _ := f.get(key)
+			if it != nil {
+				out.Responses[table] = append(out.Responses[table], it)
+			}
+		}
+	}
+	if f.UnprocessedBatchCalls > 0 {
+		f.UnprocessedBatchCalls--
+	}
+	return out, nil
+}
+
+func (f *DynamoFake) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("BatchWriteItem", nil); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for table, reqs := range params.RequestItems {
+		if table != f.tableName {
+			return nil, &types.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("table %s not found", table))}
+		}
+		total += len(reqs)
+	}
+	if total > fakeBatchWriteLimit {
+		return nil, validationError("too many items in the BatchWriteItem call: %d", total)
+	}
+
+	out := &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{}}
+	for table, reqs := range params.RequestItems {
+		if f.UnprocessedBatchCalls > 0 && len(reqs) > 1 {
+			out.UnprocessedItems[table] = reqs[len(reqs)/2:]
+			reqs = reqs[:len(reqs)/2]
+		}
+		for _, req := range reqs {
+			switch {
+			case req.PutRequest != nil:
+				if err := f.put(req.PutRequest.Item); err != nil {
+					return nil, err
+				}
+			case req.DeleteRequest != nil:
+				if err := f.delete(req.DeleteRequest.Key); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if f.UnprocessedBatchCalls > 0 {
+		f.UnprocessedBatchCalls--
+	}
+	return out, nil
+}
+
+func (f *DynamoFake) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("Query", params.TableName); err != nil {
+		return nil, err
+	}
+	if aws.ToString(params.KeyConditionExpression) == "" {
+		return nil, validationError("KeyConditionExpression is required")
+	}
+
+	exprCtx := exprContext{names: params.ExpressionAttributeNames, values: params.ExpressionAttributeValues}
+	matched := make([]item, 0)
+	for _, it := range f.sortedItems(false) {
+		ok, err := evalCondition(aws.ToString(params.KeyConditionExpression), exprCtx, it)
+		if err != nil {
+			return nil, validationError("%v", err)
+		}
+		if ok {
+			matched = append(matched, it)
+		}
+	}
+	if params.ScanIndexForward != nil && !*params.ScanIndexForward {
+		for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+			matched[i], matched[j] = matched[j], matched[i]
+		}
+	}
+
+	if len(params.ExclusiveStartKey) > 0 {
+		startPK, startSK, err := keyOf(params.ExclusiveStartKey)
+		if err != nil {
+			return nil, err
+		}
+		for i, it := range matched {
+			pk, sk, _ := keyOf(it)
+			if pk == startPK && sk == startSK {
+				matched = matched[i+1:]
+				break
+			}
+		}
+	}
+
+	limit := f.QueryPageSize
+	if params.Limit != nil && (limit == 0 || int(*params.Limit) < limit) {
+		limit = int(*params.Limit)
+	}
+	out := &dynamodb.QueryOutput{}
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+		last := matched[len(matched)-1]
+		out.LastEvaluatedKey = item{
+			constants.PartitionKey: last[constants.PartitionKey],
+			constants.SortKey:      last[constants.SortKey],
+		}
+	}
+
+	out.Items = make([]map[string]types.AttributeValue, 0, len(matched))
+	for _, it := range matched {
+		ok, err := evalCondition(aws.ToString(params.FilterExpression), exprCtx, it)
+		if err != nil {
+			return nil, validationError("%v", err)
+		}
+		if ok {
+			out.Items = append(out.Items, it)
+		}
+	}
+	out.Count = int32(len(out.Items))
+	out.ScannedCount = int32(len(matched))
+	return out, nil
+}
+
+// TransactWriteItems applies every action or none, reporting per-action cancellation reasons like DynamoDB
+func (f *DynamoFake) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin("TransactWriteItems", nil); err != nil {
+		return nil, err
+	}
+	if len(params.TransactItems) > fakeTransactItemLimit {
+		return nil, validationError("too many items in the TransactWriteItems call: %d", len(params.TransactItems))
+	}
+
+	type pendingWrite struct {
+		put    item
+		delete item
+	}
+	writes := make([]pendingWrite, 0, len(params.TransactItems))
+	reasons := make([]types.CancellationReason, len(params.TransactItems))
+	canceled := false
+	for i, action := range params.TransactItems {
+		reasons[i] = types.CancellationReason{Code: aws.String("None")}
+		var err error
+		switch {
+		case action.ConditionCheck != nil:
+			c := action.ConditionCheck
+			err = f.checkCondition(c.TableName, c.Key, c.ConditionExpression, c.ExpressionAttributeNames, c.ExpressionAttributeValues)
+		case action.Put != nil:
+			p := action.Put
+			err = f.checkCondition(p.TableName, p.Item, p.ConditionExpression, p.ExpressionAttributeNames, p.ExpressionAttributeValues)
+			writes = append(writes, pendingWrite{put: p.Item})
+		case action.Delete != nil:
+			d := action.Delete
+			err = f.checkCondition(d.TableName, d.Key, d.ConditionExpression, d.ExpressionAttributeNames, d.ExpressionAttributeValues)
+			writes = append(writes, pendingWrite{delete: d.Key})
+		case action.Update != nil:
+			u := action.Update
+			if aws.ToString(u.TableName) != f.tableName {
+				return nil, &types.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("table %s not found", aws.ToString(u.TableName)))}
+			}
+			var updated item
+			updated, _, err = f.update(u.Key, u.UpdateExpression, u.ConditionExpression, u.ExpressionAttributeNames, u.ExpressionAttributeValues)
+			writes = append(writes, pendingWrite{put: updated})
+		}
+
+		var cce *types.ConditionalCheckFailedException
+		switch {
+		case errors.As(err, &cce):
+			reasons[i] = types.CancellationReason{Code: aws.String("ConditionalCheckFailed"), Message: cce.Message}
+			canceled = true
+		case err != nil:
+			return nil, err
+		}
+	}
+	if canceled {
+		return nil, &types.TransactionCanceledException{
+			Message:             aws.String("Transaction cancelled, please refer cancellation reasons for specific reasons"),
+			CancellationReasons: reasons,
+		}
+	}
+
+	for _, w := range writes {
+		if w.put != nil {
+			if err := f.put(w.put); err != nil {
+				return nil, err
+			}
+		}
+		if w.delete != nil {
+			if err := f.delete(w.delete); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (f *DynamoFake) checkCondition(table *string, key item, condExpr *string, names map[string]string, values map[string]types.AttributeValue) error {
+	if aws.ToString(table) != f.tableName {
+		return &types.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("table %s not found", aws.ToString(table)))}
+	}
+	existing, err := f.get(key)
+	if err != nil {
+		return err
+	}
+	ok, err := evalCondition(aws.ToString(condExpr), exprContext{names: names, values: values}, existing)
+	if err != nil {
+		return validationError("%v", err)
+	}
+	if !ok {
+		return conditionFailed()
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package mocks
 import (
 	"context"
 	"fmt"
+
+	domain "github.com/ItsDee25/exchange-rate-service/internal/domain/currency"
 )
 
 // MockRateFetcher implements domain.RateFetcher
@@ -42,5 +44,5 @@ func (m *MockRateFetcher) FetchRate(ctx context.Context, from, to, date string)
 	if rate, ok := m.Rates[key]; ok {
 		return rate, nil
 	}
-	return 0, fmt.Errorf("mock rate not found for %s to %s", from, to)
+	return 0, fmt.Errorf("mock rate not found for %s to %s: %w", from, to, domain.ErrRateUnavailable)
 }
@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+// NewRateProviderServer starts an httptest stand-in for the exchangerate.host /convert api
+// serving the given rates (key: "FROM#TO") to callers using the given access key.
+func NewRateProviderServer(accessKey string, rates map[string]float64) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		query := r.URL.Query()
+		if query.Get("access_key") != accessKey {
+			writeProviderError(w, 101, "invalid_access_key", "You have not supplied a valid API Access Key.")
+			return
+		}
+		from, to := query.Get("from"), query.Get("to")
+		rate, ok := rates[fmt.Sprintf("%s#%s", from, to)]
+		if !ok {
+			writeProviderError(w, 402, "invalid_currency", fmt.Sprintf("No rate available for %s to %s.", from, to))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"success": true,
+			"query":   map[string]any{"from": from, "to": to, "amount": 1},
+			"info":    map[string]any{"quote": rate},
+			"date":    query.Get("date"),
+			"result":  rate,
+		})
+	})
+	return httptest.NewServer(mux)
+}
+
+func writeProviderError(w http.ResponseWriter, code int, errType, info string) {
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"success": false,
+		"error": map[string]any{
+			"code": code,
+			"type": errType,
+			"info": info,
+		},
+	})
+}
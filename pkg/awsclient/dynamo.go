@@ -3,21 +3,34 @@ package pkg
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 )
 
-func NewDynamoClient(ctx context.Context) (*dynamodb.Client, error) {
-	customEndpoint := os.Getenv("DYNAMO_ENDPOINT")
+// DynamoAPI is the subset of the DynamoDB api used by the repositories and the locker,
+// implemented by *dynamodb.Client and by the in-process fake in mocks.
+type DynamoAPI interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+var _ DynamoAPI = (*dynamodb.Client)(nil)
 
-	resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
-		if service == dynamodb.ServiceID && customEndpoint != "" {
+// NewDynamoClient builds a client for the region, sending requests to endpoint instead of AWS when it is set
+// (e.g. dynamodb-local).
+func NewDynamoClient(ctx context.Context, region, endpoint string) (*dynamodb.Client, error) {
+	resolver := aws.EndpointResolverWithOptionsFunc(func(service, _ string, options ...interface{}) (aws.Endpoint, error) {
+		if service == dynamodb.ServiceID && endpoint != "" {
 			return aws.Endpoint{
-				URL:               customEndpoint,
-				SigningRegion:     "us-west-2",
+				URL:               endpoint,
+				SigningRegion:     region,
 				HostnameImmutable: true,
 			}, nil
 		}
@@ -25,7 +38,7 @@ func NewDynamoClient(ctx context.Context) (*dynamodb.Client, error) {
 	})
 
 	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion("us-west-2"),
+		config.WithRegion(region),
 		config.WithEndpointResolverWithOptions(resolver),
 	)
 	if err != nil {
@@ -0,0 +1,18 @@
+package constants
+
+const (
+	// EnvRateCacheMaxEntries bounds the number of rates kept in the in-memory cache
+	EnvRateCacheMaxEntries = "RATE_CACHE_MAX_ENTRIES"
+	// EnvRateCacheCurrentTTL is how long today's rates stay cached, e.g. 2h
+	EnvRateCacheCurrentTTL = "RATE_CACHE_CURRENT_TTL"
+	// EnvRateCacheHistoricalTTL is how long rates of past dates stay cached, e.g. 24h
+	EnvRateCacheHistoricalTTL = "RATE_CACHE_HISTORICAL_TTL"
+	// EnvRateCacheNegativeTTL is how long a pair and date no provider can serve is remembered, e.g. 5m
+	EnvRateCacheNegativeTTL = "RATE_CACHE_NEGATIVE_TTL"
+	// EnvRateCacheNegativeMaxEntries bounds the negative entries, evicted apart from the rates
+	EnvRateCacheNegativeMaxEntries = "RATE_CACHE_NEGATIVE_MAX_ENTRIES"
+	// EnvRateCacheWarmupDays is how many days before today are loaded into the cache on startup
+	EnvRateCacheWarmupDays = "RATE_CACHE_WARMUP_DAYS"
+	// EnvRateCacheWarmupTimeout bounds the startup cache warm-up, e.g. 1m
+	EnvRateCacheWarmupTimeout = "RATE_CACHE_WARMUP_TIMEOUT"
+)
@@ -0,0 +1,48 @@
+package constants
+
+const (
+	// EnvConfigFile points to the JSON config file, settings not in the file keep their defaults
+	EnvConfigFile = "CONFIG_FILE"
+
+	EnvServerPort        = "PORT"
+	EnvShutdownTimeout   = "SHUTDOWN_TIMEOUT"
+	EnvHTTPClientTimeout = "HTTP_CLIENT_TIMEOUT"
+	// EnvAdminToken is the bearer token required by the admin api, leave it unset to disable the admin api
+	EnvAdminToken = "ADMIN_API_TOKEN"
+
+	EnvDynamoRegion   = "DYNAMO_REGION"
+	EnvDynamoEndpoint = "DYNAMO_ENDPOINT"
+	EnvDynamoTable    = "DYNAMO_TABLE"
+
+	// EnvSupportedCurrencies lists the currencies seeding the currency registry, e.g. USD,EUR,INR
+	EnvSupportedCurrencies = "SUPPORTED_CURRENCIES"
+	EnvRetentionDays       = "RATE_RETENTION_DAYS"
+	EnvRefresherFreq       = "REFRESHER_INTERVAL"
+	EnvRefresherLock       = "REFRESHER_LOCK_TTL"
+	// EnvRefresherConcurrency bounds the provider calls a refresher run makes at once
+	EnvRefresherConcurrency = "REFRESHER_CONCURRENCY"
+	EnvCleanerFreq          = "CACHE_CLEANER_INTERVAL"
+	EnvRegistryReloadFreq   = "CURRENCY_REGISTRY_RELOAD_INTERVAL"
+
+	EnvWriteBehindCapacity      = "WRITE_BEHIND_CAPACITY"
+	EnvWriteBehindWorkers       = "WRITE_BEHIND_WORKERS"
+	EnvWriteBehindFlushInterval = "WRITE_BEHIND_FLUSH_INTERVAL"
+	EnvWriteBehindMaxAttempts   = "WRITE_BEHIND_MAX_ATTEMPTS"
+
+	// EnvLogLevel is the lowest level logged: debug, info, warn or error
+	EnvLogLevel = "LOG_LEVEL"
+	// EnvLogFormat is json or text
+	EnvLogFormat = "LOG_FORMAT"
+
+	// EnvTracingExporter is none, stdout, file or otlp
+	EnvTracingExporter    = "TRACING_EXPORTER"
+	EnvTracingFile        = "TRACING_FILE"
+	EnvTracingEndpoint    = "TRACING_OTLP_ENDPOINT"
+	EnvTracingSampleRatio = "TRACING_SAMPLE_RATIO"
+
+	EnvHealthCheckTimeout = "HEALTH_CHECK_TIMEOUT"
+	// EnvHealthRefresherMaxAge is how old the last successful refresher run may get before the instance is not ready
+	EnvHealthRefresherMaxAge = "HEALTH_REFRESHER_MAX_AGE"
+	// EnvHealthProviderCheckInterval is how often readiness probes call the rate provider, the result is reused in between
+	EnvHealthProviderCheckInterval = "HEALTH_PROVIDER_CHECK_INTERVAL"
+)
@@ -0,0 +1,6 @@
+package constants
+
+const (
+	EnvConversionRoundingMode     = "CONVERSION_ROUNDING_MODE"
+	DefaultConversionRoundingMode = "half_even"
+)
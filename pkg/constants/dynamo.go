@@ -6,6 +6,17 @@ const (
 	TTL          = "ttl"
 	Rate         = "rate"
 	UpdatedAt    = "updated_at"
-	TableName  = "exchange_rates"
-	ExpiresAt = "expires_at"
-)
\ No newline at end of file
+	TableName    = "exchange_rates"
+	ExpiresAt    = "expires_at"
+	Provider     = "provider"
+	FetchedAt    = "fetched_at"
+	RefreshMode  = "refresh_mode"
+	Owner        = "owner"
+	Fence        = "fence"
+	LockSortKey  = "LOCK"
+	// CurrencyPartitionKey groups the currency registry items, whose sort key is the currency code
+	CurrencyPartitionKey = "CURRENCY"
+
+	// HealthCheckKey is the key read by the readiness probe, no item is stored under it
+	HealthCheckKey = "HEALTHCHECK"
+)
@@ -0,0 +1,16 @@
+package constants
+
+const (
+	ProviderMock            = "mock"
+	ProviderExchangeRateAPI = "exchangerateapi"
+	DefaultRateProvider     = ProviderExchangeRateAPI
+)
+
+const (
+	EnvRateProvider        = "RATE_PROVIDER"
+	EnvRateProviderBaseURL = "RATE_PROVIDER_BASE_URL"
+	EnvRateProviderAPIKey  = "RATE_PROVIDER_API_KEY"
+	EnvRateProviderTimeout = "RATE_PROVIDER_TIMEOUT"
+	// EnvRateProviderQuorumTolerance enables quorum mode when set to a positive relative tolerance, e.g. 0.005
+	EnvRateProviderQuorumTolerance = "RATE_PROVIDER_QUORUM_TOLERANCE"
+)
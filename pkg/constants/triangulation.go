@@ -0,0 +1,7 @@
+package constants
+
+const (
+	// EnvTriangulationPivots lists the pivot currencies used to derive missing pairs, set it empty to disable triangulation
+	EnvTriangulationPivots     = "TRIANGULATION_PIVOTS"
+	DefaultTriangulationPivots = "USD,EUR"
+)
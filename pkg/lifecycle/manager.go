@@ -0,0 +1,93 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager runs the service until SIGINT/SIGTERM and then shuts its components down in registration order,
+// all within one shutdown deadline.
+type Manager struct {
+	hooks           []hook
+	shutdownTimeout time.Duration
+	logger          *slog.Logger
+}
+
+func NewManager(shutdownTimeout time.Duration) *Manager {
+	return &Manager{
+		shutdownTimeout: shutdownTimeout,
+		logger:          slog.Default().With("component", "Lifecycle"),
+	}
+}
+
+func (m *Manager) WithLogger(logger *slog.Logger) *Manager {
+	m.logger = logger.With("component", "Lifecycle")
+	return m
+}
+
+// OnShutdown registers a component to stop, hooks run one after another in the order they were registered
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) *Manager {
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+	return m
+}
+
+// Run calls serve and blocks until it returns or a termination signal arrives, then runs the shutdown hooks.
+// serve must return once the hook stopping it has run, e.g. http.Server.ListenAndServe after Shutdown.
+func (m *Manager) Run(serve func() error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve()
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+		if err == nil || errors.Is(err, http.ErrServerClosed) {
+			// closed without a signal, e.g. by another component, still a clean stop
+			err = nil
+			m.logger.Info("server stopped")
+		} else {
+			m.logger.Error("server stopped", "error", err)
+		}
+	case <-ctx.Done():
+		m.logger.Info("termination signal received, shutting down")
+	}
+	// a second signal kills the process right away
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+	if shutdownErr := m.shutdown(shutdownCtx); shutdownErr != nil {
+		err = errors.Join(err, shutdownErr)
+	}
+	return err
+}
+
+func (m *Manager) shutdown(ctx context.Context) error {
+	errs := make([]error, 0)
+	for _, h := range m.hooks {
+		start := time.Now()
+		if err := h.fn(ctx); err != nil {
+			m.logger.Error("failed to stop component", "name", h.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+		m.logger.Info("stopped component", "name", h.name, "duration", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,153 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logRecord is the part of a JSON log line the tests look at
+type logRecord struct {
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of a logger
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) records(t *testing.T) []logRecord {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	records := make([]logRecord, 0)
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var record logRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func newTestManager(timeout time.Duration) (*Manager, *syncBuffer) {
+	logs := &syncBuffer{}
+	return NewManager(timeout).WithLogger(slog.New(slog.NewJSONHandler(logs, nil))), logs
+}
+
+func TestRunLogsServerStop(t *testing.T) {
+	errBind := errors.New("listen tcp :8080: bind: address already in use")
+	tests := []struct {
+		name      string
+		serveErr  error
+		wantLevel string
+		wantErr   error
+	}{
+		{name: "clean stop", serveErr: nil, wantLevel: "INFO"},
+		{name: "server closed", serveErr: http.ErrServerClosed, wantLevel: "INFO"},
+		{name: "serve failure", serveErr: errBind, wantLevel: "ERROR", wantErr: errBind},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, logs := newTestManager(time.Second)
+
+			err := m.Run(func() error { return tt.serveErr })
+
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("Run() = %v, want %v", err, tt.wantErr)
+			}
+			for _, record := range logs.records(t) {
+				if record.Msg == "server stopped" {
+					if record.Level != tt.wantLevel {
+						t.Errorf("server stopped logged at %s, want %s", record.Level, tt.wantLevel)
+					}
+					return
+				}
+			}
+			t.Error("server stop not logged")
+		})
+	}
+}
+
+func TestRunShutsDownInRegistrationOrder(t *testing.T) {
+	const timeout = time.Second
+	m, _ := newTestManager(timeout)
+	var order []string
+	deadlines := map[time.Time]bool{}
+	for _, name := range []string{"http server", "jobs", "write-behind queue", "tracing"} {
+		m.OnShutdown(name, func(ctx context.Context) error {
+			deadline, ok := ctx.Deadline()
+			if !ok || deadline.After(time.Now().Add(timeout)) {
+				t.Errorf("%s: deadline = %v, %v, want one within %v", name, deadline, ok, timeout)
+			}
+			deadlines[deadline] = true
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	if err := m.Run(func() error { return nil }); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if want := []string{"http server", "jobs", "write-behind queue", "tracing"}; strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("hooks ran in order %v, want %v", order, want)
+	}
+	if len(deadlines) != 1 {
+		t.Errorf("hooks got %d deadlines, want a single shutdown deadline", len(deadlines))
+	}
+}
+
+func TestRunSharesTheShutdownDeadline(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	m, _ := newTestManager(timeout)
+	errFlush := errors.New("flush failed")
+	var ran []string
+	m.OnShutdown("stuck", func(ctx context.Context) error {
+		ran = append(ran, "stuck")
+		<-ctx.Done()
+		return ctx.Err()
+	}).OnShutdown("failing", func(ctx context.Context) error {
+		ran = append(ran, "failing")
+		return errFlush
+	}).OnShutdown("late", func(ctx context.Context) error {
+		ran = append(ran, "late")
+		if ctx.Err() == nil {
+			t.Error("hook after the deadline got a live context")
+		}
+		return nil
+	})
+
+	start := time.Now()
+	err := m.Run(func() error { return nil })
+	elapsed := time.Since(start)
+
+	if len(ran) != 3 {
+		t.Errorf("hooks run = %v, want every hook run even after a failure or the deadline", ran)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errFlush) {
+		t.Errorf("Run() = %v, want the errors of both failed hooks", err)
+	}
+	if !strings.Contains(err.Error(), "stuck: ") || !strings.Contains(err.Error(), "failing: ") {
+		t.Errorf("Run() = %v, want the errors named after their hooks", err)
+	}
+	if elapsed > timeout+time.Second {
+		t.Errorf("shutdown took %v, want it bounded by the %v deadline", elapsed, timeout)
+	}
+}
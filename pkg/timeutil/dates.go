@@ -0,0 +1,29 @@
+package timeutil
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ItsDee25/exchange-rate-service/pkg/constants"
+)
+
+// DatesBetween returns every date from start to end (inclusive) in constants.DateLayout.
+func DatesBetween(start, end string) ([]string, error) {
+	startDate, err := time.Parse(constants.DateLayout, start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date %q: %w", start, err)
+	}
+	endDate, err := time.Parse(constants.DateLayout, end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date %q: %w", end, err)
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date %s is before start date %s", end, start)
+	}
+
+	dates := make([]string, 0)
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format(constants.DateLayout))
+	}
+	return dates, nil
+}